@@ -0,0 +1,107 @@
+package hasher
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// adaptiveMaxFactor is the largest multiple of the hasher's iteration count an
+	// adaptive iteration count can be raised to, see WithAdaptiveCost.
+	adaptiveMaxFactor = 16
+
+	// adaptiveMaxStep is the largest fraction an adaptive iteration count is
+	// raised or lowered by in a single adjustment.
+	adaptiveMaxStep = 0.25
+)
+
+// adaptiveCost tracks the latency of recent derivations, adjusting the iteration
+// count used for new hashes, so they take close to the target latency.
+//
+// An adaptiveCost is safe for concurrent use.
+type adaptiveCost struct {
+	mu       sync.Mutex
+	target   time.Duration
+	every    int
+	min, max int
+
+	iterCnt int
+	samples int
+	total   time.Duration
+}
+
+// returns a new adaptiveCost, starting from, and never falling below, the given
+// iteration count, and never exceeding adaptiveMaxFactor times it, or the largest
+// iteration count which can be stored in a header.
+func newAdaptiveCost(target time.Duration, every, iterCnt int) *adaptiveCost {
+	max := math.MaxInt32
+	if iterCnt < max/adaptiveMaxFactor {
+		max = iterCnt * adaptiveMaxFactor
+	}
+
+	return &adaptiveCost{
+		target:  target,
+		every:   every,
+		min:     iterCnt,
+		max:     max,
+		iterCnt: iterCnt,
+	}
+}
+
+// returns the iteration count to use for a new hash.
+func (c *adaptiveCost) iterations() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.iterCnt
+}
+
+// records the time taken to derive a key with the given iteration count, adjusting
+// the iteration count once enough derivations have been recorded. Derivations using
+// an iteration count which has since been adjusted are ignored, as they no longer
+// reflect the current cost.
+func (c *adaptiveCost) observe(iterCnt int, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if iterCnt != c.iterCnt {
+		return
+	}
+
+	c.samples++
+	c.total += d
+
+	if c.samples < c.every {
+		return
+	}
+
+	avg := c.total / time.Duration(c.samples)
+	c.samples, c.total = 0, 0
+
+	// scale the iteration count by the ratio of the target to the average
+	// latency, limiting the step, so a single slow batch can't swing it far.
+	ratio := 1 + adaptiveMaxStep
+	if avg > 0 {
+		ratio = float64(c.target) / float64(avg)
+	}
+
+	if ratio > 1+adaptiveMaxStep {
+		ratio = 1 + adaptiveMaxStep
+	}
+
+	if ratio < 1-adaptiveMaxStep {
+		ratio = 1 - adaptiveMaxStep
+	}
+
+	n := int(float64(c.iterCnt) * ratio)
+	if n < c.min {
+		n = c.min
+	}
+
+	if n > c.max {
+		n = c.max
+	}
+
+	c.iterCnt = n
+}
@@ -0,0 +1,78 @@
+package hasher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveCost(t *testing.T) {
+	c := newAdaptiveCost(10*time.Millisecond, 2, DefaultIterationCount)
+
+	adjust := func(d time.Duration) {
+		c.observe(c.iterations(), d)
+		c.observe(c.iterations(), d)
+	}
+
+	observe := func(d time.Duration, expected int) {
+		t.Helper()
+
+		adjust(d)
+		if n := c.iterations(); n != expected {
+			t.Errorf("expected %d iterations, but got %d", expected, n)
+		}
+	}
+
+	// derivations faster than the target raise the count, by at most 25%.
+	observe(2*time.Millisecond, 1250)
+	observe(8*time.Millisecond, 1562)
+
+	// but never more than 16 times the initial count.
+	for i := 0; i < 20; i++ {
+		adjust(0)
+	}
+
+	observe(0, 16*DefaultIterationCount)
+
+	// derivations slower than the target lower the count, by at most 25%.
+	observe(time.Second, 12000)
+	observe(12500*time.Microsecond, 9600)
+
+	// but never less than the initial count.
+	for i := 0; i < 20; i++ {
+		adjust(time.Second)
+	}
+
+	observe(time.Second, DefaultIterationCount)
+
+	t.Run("Interval", func(t *testing.T) {
+		c := newAdaptiveCost(10*time.Millisecond, 3, DefaultIterationCount)
+
+		c.observe(DefaultIterationCount, time.Millisecond)
+		c.observe(DefaultIterationCount, time.Millisecond)
+		if n := c.iterations(); n != DefaultIterationCount {
+			t.Errorf("expected %d iterations, but got %d", DefaultIterationCount, n)
+		}
+
+		c.observe(DefaultIterationCount, time.Millisecond)
+		if n := c.iterations(); n != 1250 {
+			t.Errorf("expected %d iterations, but got %d", 1250, n)
+		}
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		c := newAdaptiveCost(10*time.Millisecond, 1, DefaultIterationCount)
+
+		// derivations using an old iteration count are ignored.
+		c.observe(DefaultIterationCount/2, time.Millisecond)
+		if n := c.iterations(); n != DefaultIterationCount {
+			t.Errorf("expected %d iterations, but got %d", DefaultIterationCount, n)
+		}
+	})
+
+	t.Run("Max", func(t *testing.T) {
+		c := newAdaptiveCost(time.Second, 1, 1<<30)
+		if c.max != 1<<31-1 {
+			t.Errorf("expected a max of %d, but got %d", 1<<31-1, c.max)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package hasher
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// IsSuspectHash returns true if the hash matches a pattern known to be produced
+// by a bug, meaning the hash should be treated as insecure and the password reset.
+// This can be used to sweep stored hashes for remediation.
+//
+// The patterns flagged are:
+//   - a salt which is empty, or consists entirely of zero bytes, as produced by
+//     hashing while the random number generator was failing,
+//   - an iteration count of zero, which New does not permit.
+//
+// Hashes which are in an invalid format are not flagged. It's not possible to detect
+// every affected hash, so a false result does not guarantee a hash is sound.
+func IsSuspectHash(hash []byte) bool {
+	hdr, err := scanHeader(hash)
+	if err == ErrEmptySalt {
+		return true
+	}
+
+	if err != nil {
+		return false
+	}
+
+	if hdr.iterCnt == 0 {
+		return true
+	}
+
+	for _, b := range hash[hdr.size : hdr.size+hdr.saltLen] {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AuditFormat is the encoding of the hashes read by Audit.
+type AuditFormat int
+
+// Supported audit formats.
+const (
+	// AuditBase64 is standard, padded base64.
+	AuditBase64 AuditFormat = iota
+
+	// AuditBase64URL is URL-safe, padded base64.
+	AuditBase64URL
+
+	// AuditHex is hexadecimal.
+	AuditHex
+)
+
+// decodes a single hash in the format.
+func (f AuditFormat) decode(s string) ([]byte, error) {
+	switch f {
+	case AuditBase64:
+		return base64.StdEncoding.DecodeString(s)
+	case AuditBase64URL:
+		return base64.URLEncoding.DecodeString(s)
+	case AuditHex:
+		return hex.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unsupported audit format: %d", f)
+	}
+}
+
+// AuditReport summarises a set of hashes read by Audit.
+type AuditReport struct {
+	// Total is the number of hashes read, including those with errors.
+	Total int
+
+	// Errors is the number of lines which could not be decoded or inspected.
+	Errors int
+
+	// Algorithms maps hash keys to the number of hashes using them.
+	Algorithms map[int]int
+
+	// Iterations maps iteration counts to the number of hashes using them.
+	Iterations map[int]int
+
+	// NeedsRehash is the number of hashes which need rehashing to
+	// meet the target hasher's parameters, excluding errors.
+	NeedsRehash int
+}
+
+// Audit reads newline-delimited, encoded hashes from r, inspecting each and
+// producing a report of the algorithms and iteration counts used, and how many
+// hashes need rehashing to meet the target hasher's parameters. Blank lines are
+// ignored. Lines which are malformed are counted as errors, rather than aborting.
+//
+// A non-nil error will only be returned if r could not be read.
+func Audit(r io.Reader, format AuditFormat, target Hasher) (AuditReport, error) {
+	report := AuditReport{
+		Algorithms: make(map[int]int),
+		Iterations: make(map[int]int),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		report.Total++
+
+		hash, err := format.decode(line)
+		if err != nil {
+			report.Errors++
+			continue
+		}
+
+		info, err := Inspect(hash)
+		if err != nil {
+			report.Errors++
+			continue
+		}
+
+		report.Algorithms[info.Algorithm]++
+		report.Iterations[info.Iterations]++
+
+		if target.NeedsRehash(hash) {
+			report.NeedsRehash++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
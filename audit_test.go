@@ -0,0 +1,97 @@
+package hasher
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestIsSuspectHash(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	t.Run("Sound", func(t *testing.T) {
+		if IsSuspectHash(mustHash(t, pwd)) {
+			t.Errorf("didn't expect hash to be suspect")
+		}
+	})
+
+	t.Run("Zero Salt", func(t *testing.T) {
+		hash, _ := Hash(pwd)
+		hdr, _ := scanHeader(hash)
+		copy(hash[hdr.size:hdr.size+hdr.saltLen], make([]byte, hdr.saltLen))
+
+		if !IsSuspectHash(hash) {
+			t.Errorf("expected hash to be suspect")
+		}
+	})
+
+	t.Run("Empty Salt", func(t *testing.T) {
+		hash := make([]byte, headerSizeV2+32)
+		writeHeader(hash, header{version: HeaderVersion, hashKey: DefaultHashKey, iterCnt: DefaultIterationCount})
+
+		if !IsSuspectHash(hash) {
+			t.Errorf("expected hash to be suspect")
+		}
+	})
+
+	t.Run("Zero Iterations", func(t *testing.T) {
+		hash, _ := Hash(pwd)
+		writeHeaderValue(hash, 7, 0)
+
+		if !IsSuspectHash(hash) {
+			t.Errorf("expected hash to be suspect")
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if IsSuspectHash([]byte{0x23}) {
+			t.Errorf("didn't expect hash to be suspect")
+		}
+	})
+}
+
+func TestAudit(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	target, _ := New(5000, DefaultSaltSize, DefaultKeySize, HashSHA256)
+	weak, _ := New(1000, DefaultSaltSize, DefaultKeySize, HashSHA512)
+
+	var lines []string
+	for i := 0; i < 3; i++ {
+		hash, _ := target.Hash(pwd)
+		lines = append(lines, base64.StdEncoding.EncodeToString(hash))
+	}
+
+	hash, _ := weak.Hash(pwd)
+	lines = append(lines,
+		base64.StdEncoding.EncodeToString(hash),
+		"",
+		"not base64!",
+		base64.StdEncoding.EncodeToString([]byte{0x23}),
+	)
+
+	report, err := Audit(strings.NewReader(strings.Join(lines, "\n")), AuditBase64, target)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if report.Total != 6 {
+		t.Errorf("expected a total of 6, but got %d", report.Total)
+	}
+
+	if report.Errors != 2 {
+		t.Errorf("expected 2 errors, but got %d", report.Errors)
+	}
+
+	if report.Algorithms[HashSHA256] != 3 || report.Algorithms[HashSHA512] != 1 {
+		t.Errorf("unexpected algorithms: %v", report.Algorithms)
+	}
+
+	if report.Iterations[5000] != 3 || report.Iterations[1000] != 1 {
+		t.Errorf("unexpected iterations: %v", report.Iterations)
+	}
+
+	if report.NeedsRehash != 1 {
+		t.Errorf("expected 1 hash to need rehashing, but got %d", report.NeedsRehash)
+	}
+}
@@ -0,0 +1,130 @@
+package hasher
+
+import "errors"
+
+// ErrInvalidBase58 is returned when a string is not valid Base58.
+var ErrInvalidBase58 = errors.New("string is not valid base58")
+
+// base58Alphabet is the Base58 alphabet used by Bitcoin, which omits
+// characters that are easily confused, such as '0', 'O', 'I' and 'l'.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Index maps each character of the alphabet to its value, or -1.
+var base58Index = func() [256]int {
+	var index [256]int
+	for i := range index {
+		index[i] = -1
+	}
+
+	for i := 0; i < len(base58Alphabet); i++ {
+		index[base58Alphabet[i]] = i
+	}
+
+	return index
+}()
+
+// HashBase58 hashes the given password using the default hasher, in the same
+// way as Hash, returning the hash encoded using Base58, with Bitcoin's alphabet.
+//
+// A non-nil error will be returned if a salt could not be generated.
+func HashBase58(pwd []byte) (string, error) {
+	hash, err := defaultHasher.Hash(pwd)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeBase58(hash), nil
+}
+
+// VerifyBase58 verifies the password against a hash produced by HashBase58, using
+// the default hasher, returning a flag which determines whether or not the password
+// matches the hash.
+//
+// ErrInvalidBase58 will be returned if the string is not valid Base58, so it can be
+// distinguished from a password which doesn't match.
+func VerifyBase58(pwd []byte, s string) (bool, error) {
+	hash, err := decodeBase58(s)
+	if err != nil {
+		return false, err
+	}
+
+	return defaultHasher.Verify(pwd, hash), nil
+}
+
+// encodes the data using Base58, where each leading zero byte is encoded as '1'.
+func encodeBase58(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) is ~1.37, so this is enough digits for any input.
+	digits := make([]byte, 0, len(data)*138/100+1)
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = base58Alphabet[0]
+	}
+
+	// the digits are little-endian.
+	for i, d := range digits {
+		out[len(out)-1-i] = base58Alphabet[d]
+	}
+
+	return string(out)
+}
+
+// decodes a Base58 string, returning ErrInvalidBase58 if it
+// contains a character outside of the alphabet.
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, ErrInvalidBase58
+	}
+
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	// log(58) / log(256) is ~0.73, so this is enough bytes for any input.
+	data := make([]byte, 0, len(s)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		carry := base58Index[s[i]]
+		if carry < 0 {
+			return nil, ErrInvalidBase58
+		}
+
+		for j := range data {
+			carry += int(data[j]) * 58
+			data[j] = byte(carry)
+			carry >>= 8
+		}
+
+		for carry > 0 {
+			data = append(data, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(data))
+
+	// the bytes are little-endian.
+	for i, b := range data {
+		out[len(out)-1-i] = b
+	}
+
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package hasher
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Data    []byte
+		Encoded string
+	}{
+		{Name: "Empty", Data: []byte{}, Encoded: ""},
+		{Name: "Zero", Data: []byte{0}, Encoded: "1"},
+		{Name: "Leading Zeros", Data: []byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, Encoded: "11233QC4"},
+		{Name: "Text", Data: []byte("Hello World!"), Encoded: "2NEpo7TZRRrLZSi2U"},
+		{Name: "Max Byte", Data: []byte{0xff}, Encoded: "5Q"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if s := encodeBase58(tc.Data); s != tc.Encoded {
+				t.Errorf("expected '%s' but got '%s'", tc.Encoded, s)
+			}
+
+			if tc.Encoded == "" {
+				return
+			}
+
+			data, err := decodeBase58(tc.Encoded)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+			}
+
+			if !bytes.Equal(data, tc.Data) {
+				t.Errorf("expected '%x' but got '%x'", tc.Data, data)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, s := range []string{"", "0", "2NEpo7TZRRrLZSi2O", "abc l"} {
+			if _, err := decodeBase58(s); err != ErrInvalidBase58 {
+				t.Errorf("%q: expected '%v' but got '%v'", s, ErrInvalidBase58, err)
+			}
+		}
+	})
+}
+
+func TestHashBase58(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	s, err := HashBase58(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	hash, _ := decodeBase58(s)
+	if !Verify(pwd, hash) {
+		t.Errorf("expected the decoded hash to be valid")
+	}
+
+	if ok, err := VerifyBase58(pwd, s); !ok || err != nil {
+		t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		if ok, err := VerifyBase58([]byte("wrong"), s); ok || err != nil {
+			t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if ok, err := VerifyBase58(pwd, s+"0"); ok || err != ErrInvalidBase58 {
+			t.Errorf("expected (false, '%v') but got (%v, %v)", ErrInvalidBase58, ok, err)
+		}
+	})
+}
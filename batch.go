@@ -0,0 +1,72 @@
+package hasher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// VerifyJob is a password and hash pair, to be verified by VerifyBatch.
+type VerifyJob struct {
+	Pwd  []byte
+	Hash []byte
+}
+
+// VerifyBatch verifies each job's password against its hash, in the same way as Verify,
+// spreading the jobs across GOMAXPROCS goroutines, for bulk, offline checks, such as
+// auditing a dump of hashes against known passwords. The result for each job is stored
+// at the same index as the job.
+func (h *hasher) VerifyBatch(jobs []VerifyJob) []bool {
+	results, _ := h.VerifyBatchContext(context.Background(), jobs)
+	return results
+}
+
+// VerifyBatchContext verifies the jobs in the same way as VerifyBatch. If the context
+// is done, no more jobs are started, and jobs in progress are stopped, so their results,
+// and those of jobs never started, are false, and the context's error is returned.
+func (h *hasher) VerifyBatchContext(ctx context.Context, jobs []VerifyJob) ([]bool, error) {
+	return verifyBatch(ctx, h, jobs)
+}
+
+// verifies the jobs using the Hasher, with a bounded pool of GOMAXPROCS workers.
+func verifyBatch(ctx context.Context, h Hasher, jobs []VerifyJob) ([]bool, error) {
+	results := make([]bool, len(jobs))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			// each index is received by a single worker, so
+			// results can be written without a lock.
+			for i := range indexes {
+				results[i], _ = h.VerifyContext(ctx, jobs[i].Pwd, jobs[i].Hash)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
@@ -0,0 +1,75 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	h, _ := New(1000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+	var jobs []VerifyJob
+	var expected []bool
+	for i := 0; i < 20; i++ {
+		pwd := []byte{'p', byte('a' + i)}
+		hash, err := h.Hash(pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		// every third job has the wrong password.
+		if i%3 == 0 {
+			pwd = []byte("wrong")
+		}
+
+		jobs = append(jobs, VerifyJob{Pwd: pwd, Hash: hash})
+		expected = append(expected, i%3 != 0)
+	}
+
+	jobs = append(jobs, VerifyJob{Pwd: []byte("pwd"), Hash: []byte("invalid")})
+	expected = append(expected, false)
+
+	results := h.VerifyBatch(jobs)
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results but got %d", len(expected), len(results))
+	}
+
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("expected job %d to be '%v' but got '%v'", i, expected[i], results[i])
+		}
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		if results := h.VerifyBatch(nil); len(results) != 0 {
+			t.Errorf("expected no results but got %d", len(results))
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := h.VerifyBatchContext(ctx, jobs)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected '%v' but got '%v'", context.Canceled, err)
+		}
+
+		for i, ok := range results {
+			if ok {
+				t.Errorf("expected job %d not to be verified", i)
+			}
+		}
+	})
+
+	t.Run("Noop", func(t *testing.T) {
+		var n NoopHasher
+		hash, _ := n.Hash([]byte("pwd"))
+
+		results := n.VerifyBatch([]VerifyJob{{Pwd: []byte("pwd"), Hash: hash}, {Pwd: []byte("other"), Hash: hash}})
+		if !results[0] || results[1] {
+			t.Errorf("expected '[true false]' but got '%v'", results)
+		}
+	})
+}
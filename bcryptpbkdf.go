@@ -0,0 +1,116 @@
+package hasher
+
+import (
+	"crypto/sha512"
+	"errors"
+
+	"golang.org/x/crypto/blowfish"
+)
+
+// ErrInvalidBcryptPBKDFInput is returned when deriving a key with HashBcryptPBKDF,
+// if the password or salt is empty, or the salt is longer than 1MiB.
+var ErrInvalidBcryptPBKDFInput = errors.New("bcrypt_pbkdf requires a non-empty password and a salt of 1 to 2^20 bytes")
+
+const (
+	// bcryptPBKDFBlockSize is the number of bytes produced by each bcrypt hash.
+	bcryptPBKDFBlockSize = 32
+
+	// bcryptPBKDFMaxKeyLen is the maximum key length accepted by bcrypt_pbkdf.
+	bcryptPBKDFMaxKeyLen = 1024
+
+	// bcryptPBKDFMaxSaltLen is the maximum salt length accepted by bcrypt_pbkdf.
+	bcryptPBKDFMaxSaltLen = 1 << 20
+)
+
+// bcryptPBKDFMagic is the plaintext encrypted by each bcrypt hash.
+var bcryptPBKDFMagic = []byte("OxychromaticBlowfishSwatDynamite")
+
+// derives a key of keyLen bytes using OpenBSD's bcrypt_pbkdf(3), as used by
+// OpenSSH to encrypt private keys, where the iteration count is the number of
+// rounds. Each block is derived in the same way as pbkdf2, but using a bcrypt
+// based hash, with the output bytes interleaved across the blocks.
+func bcryptPBKDF(pwd, salt []byte, rounds, keyLen int) ([]byte, error) {
+	if rounds < 1 {
+		return nil, ErrInvalidIterationCount
+	}
+
+	if keyLen < 1 {
+		return nil, ErrInvalidKeyLength
+	}
+
+	if keyLen > bcryptPBKDFMaxKeyLen {
+		return nil, ErrKeyTooLarge
+	}
+
+	if len(pwd) == 0 || len(salt) == 0 || len(salt) > bcryptPBKDFMaxSaltLen {
+		return nil, ErrInvalidBcryptPBKDFInput
+	}
+
+	numBlocks := (keyLen + bcryptPBKDFBlockSize - 1) / bcryptPBKDFBlockSize
+	key := make([]byte, numBlocks*bcryptPBKDFBlockSize)
+
+	h := sha512.New()
+	h.Write(pwd)
+	shaPwd := h.Sum(nil)
+
+	shaSalt := make([]byte, 0, sha512.Size)
+	tmp := make([]byte, bcryptPBKDFBlockSize)
+	out := make([]byte, bcryptPBKDFBlockSize)
+	var buf [4]byte
+
+	for block := 1; block <= numBlocks; block++ {
+		h.Reset()
+		h.Write(salt)
+		buf[0] = byte(block >> 24)
+		buf[1] = byte(block >> 16)
+		buf[2] = byte(block >> 8)
+		buf[3] = byte(block)
+		h.Write(buf[:4])
+		bcryptHash(tmp, shaPwd, h.Sum(shaSalt))
+		copy(out, tmp)
+
+		for n := 2; n <= rounds; n++ {
+			h.Reset()
+			h.Write(tmp)
+			bcryptHash(tmp, shaPwd, h.Sum(shaSalt))
+
+			for i := range out {
+				out[i] ^= tmp[i]
+			}
+		}
+
+		// the output of each block is spread across the key, so that
+		// truncating the key discards bytes from every block.
+		for i, b := range out {
+			key[i*numBlocks+(block-1)] = b
+		}
+	}
+
+	return key[:keyLen], nil
+}
+
+// hashes the password and salt digests using a modified bcrypt, writing 32 bytes to out.
+func bcryptHash(out, shaPwd, shaSalt []byte) {
+	c, err := blowfish.NewSaltedCipher(shaPwd, shaSalt)
+	if err != nil {
+		// this should never occur, as the key is always a 64-byte digest.
+		panic(err)
+	}
+
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(shaSalt, c)
+		blowfish.ExpandKey(shaPwd, c)
+	}
+
+	copy(out, bcryptPBKDFMagic)
+	for i := 0; i < bcryptPBKDFBlockSize; i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(out[i:i+8], out[i:i+8])
+		}
+	}
+
+	// bcrypt_pbkdf reads the ciphertext as little-endian 32-bit words.
+	for i := 0; i < bcryptPBKDFBlockSize; i += 4 {
+		out[i], out[i+1], out[i+2], out[i+3] = out[i+3], out[i+2], out[i+1], out[i]
+	}
+}
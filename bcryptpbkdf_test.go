@@ -0,0 +1,85 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestBcryptPBKDF(t *testing.T) {
+	// test vectors generated by OpenBSD's reference implementation.
+	testCases := []struct {
+		Name     string
+		Pwd      string
+		Salt     string
+		Rounds   int
+		Expected string
+	}{
+		{
+			Name:     "Simple",
+			Pwd:      "password",
+			Salt:     "salt",
+			Rounds:   12,
+			Expected: "1ae42c05d487bc02f64921a4ebe4ea93bcacfe135fda99974c06b7b01fae149a",
+		},
+		{
+			Name:     "Null Bytes",
+			Pwd:      "passwordy\x00PASSWORD\x00",
+			Salt:     "salty\x00SALT\x00",
+			Rounds:   3,
+			Expected: "7f310bd3e78c3280c59ce4595211a2928e8d4ec744c1ed2efc9f764e3388e0ad",
+		},
+		{
+			Name:   "Multiple Blocks",
+			Pwd:    "секретное слово",
+			Salt:   "посолить немножко",
+			Rounds: 8,
+			Expected: "8df43fc6fe131fc47f0c9e39224bd94c70b6fcc8ee8135faddf61156e6cb2733" +
+				"ea765f315a3e1e4afc35bf8687d189254c1e05a6fe80c0617f9183d67260d6a1" +
+				"15c6c94e3603e2303fbb43a76a64523ffda686b1d4518543",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			expected, _ := hex.DecodeString(tc.Expected)
+
+			key, err := DeriveKey([]byte(tc.Pwd), []byte(tc.Salt), tc.Rounds, len(expected), HashBcryptPBKDF)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+				return
+			}
+
+			if hex.EncodeToString(key) != tc.Expected {
+				t.Errorf("expected '%s' but got '%x'", tc.Expected, key)
+			}
+
+			if !VerifyHeaderless([]byte(tc.Pwd), []byte(tc.Salt), key, tc.Rounds, HashBcryptPBKDF) {
+				t.Errorf("expected key to be valid")
+			}
+		})
+	}
+
+	t.Run("Invalid Input", func(t *testing.T) {
+		_, err := DeriveKey([]byte{}, []byte("salt"), 1, 32, HashBcryptPBKDF)
+		if err != ErrInvalidBcryptPBKDFInput {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidBcryptPBKDFInput, err)
+		}
+
+		_, err = DeriveKey([]byte("password"), []byte{}, 1, 32, HashBcryptPBKDF)
+		if err != ErrInvalidBcryptPBKDFInput {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidBcryptPBKDFInput, err)
+		}
+
+		_, err = DeriveKey([]byte("password"), []byte("salt"), 1, 1025, HashBcryptPBKDF)
+		if err != ErrKeyTooLarge {
+			t.Errorf("expected '%v' but got '%v'", ErrKeyTooLarge, err)
+		}
+	})
+
+	t.Run("Not Supported By New", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashBcryptPBKDF)
+		if err != ErrUnsupportedHashKey {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedHashKey, err)
+		}
+	})
+}
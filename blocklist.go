@@ -0,0 +1,33 @@
+package hasher
+
+// BloomFilter is a set of compromised passwords, such as a bloom filter built
+// from breach lists, used by VerifyNotCompromised. This library doesn't ship
+// a blocklist, so one must be provided by the caller.
+type BloomFilter interface {
+	// MightContain returns true if the password may be in the set. As with a
+	// bloom filter, false positives are allowed, but false negatives are not.
+	MightContain(pwd []byte) bool
+}
+
+// VerifyNotCompromised verifies the password against the hash, in the same way as
+// Verify, and checks whether it might appear in the blocklist, so a user whose password
+// is found in a breach can be prompted to reset it. Compromised is only true if the
+// password is verified, as an unverified password says nothing about the user's.
+//
+// The blocklist is checked regardless of whether or not the password is verified, so the
+// time taken doesn't reveal the result of verification. If blocklist is nil, compromised
+// is always false.
+func (h *hasher) VerifyNotCompromised(pwd, hash []byte, blocklist BloomFilter) (verified, compromised bool) {
+	return verifyNotCompromised(h, pwd, hash, blocklist)
+}
+
+// verifies the password against the hash using the Hasher, and checks the blocklist.
+func verifyNotCompromised(h Hasher, pwd, hash []byte, blocklist BloomFilter) (verified, compromised bool) {
+	verified = h.Verify(pwd, hash)
+
+	if blocklist != nil {
+		compromised = blocklist.MightContain(pwd)
+	}
+
+	return verified, verified && compromised
+}
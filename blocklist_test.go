@@ -0,0 +1,56 @@
+package hasher
+
+import "testing"
+
+// setBlocklist is a BloomFilter backed by a set, which records whether it was checked.
+type setBlocklist struct {
+	pwds    map[string]bool
+	checked bool
+}
+
+func (b *setBlocklist) MightContain(pwd []byte) bool {
+	b.checked = true
+	return b.pwds[string(pwd)]
+}
+
+func TestVerifyNotCompromised(t *testing.T) {
+	pwd := []byte("password123")
+	hash := mustHash(t, pwd)
+
+	testCases := []struct {
+		Name        string
+		Pwd         []byte
+		Blocklist   []string
+		Verified    bool
+		Compromised bool
+	}{
+		{Name: "Compromised", Pwd: pwd, Blocklist: []string{"password123"}, Verified: true, Compromised: true},
+		{Name: "Not Compromised", Pwd: pwd, Blocklist: []string{"letmein"}, Verified: true, Compromised: false},
+		{Name: "Wrong Password", Pwd: []byte("letmein"), Blocklist: []string{"letmein"}, Verified: false, Compromised: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			blocklist := &setBlocklist{pwds: map[string]bool{}}
+			for _, p := range tc.Blocklist {
+				blocklist.pwds[p] = true
+			}
+
+			verified, compromised := defaultHasher.VerifyNotCompromised(tc.Pwd, hash, blocklist)
+			if verified != tc.Verified || compromised != tc.Compromised {
+				t.Errorf("expected (%v, %v) but got (%v, %v)", tc.Verified, tc.Compromised, verified, compromised)
+			}
+
+			if !blocklist.checked {
+				t.Errorf("expected the blocklist to be checked")
+			}
+		})
+	}
+
+	t.Run("Nil Blocklist", func(t *testing.T) {
+		verified, compromised := defaultHasher.VerifyNotCompromised(pwd, hash, nil)
+		if !verified || compromised {
+			t.Errorf("expected (true, false) but got (%v, %v)", verified, compromised)
+		}
+	})
+}
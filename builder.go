@@ -0,0 +1,62 @@
+package hasher
+
+// PasswordBuilder accumulates a password written in pieces, such as a passphrase
+// assembled from multiple sources, so it can be hashed or verified without the
+// caller concatenating the pieces. As pbkdf2 uses the whole password as its HMAC
+// key, the pieces are buffered until the password is hashed or verified.
+//
+// Call Wipe once finished with the password, to zero the buffer.
+type PasswordBuilder struct {
+	h   Hasher
+	buf []byte
+}
+
+// NewPasswordBuilder returns a new PasswordBuilder, which hashes and verifies
+// passwords using the given Hasher.
+func NewPasswordBuilder(h Hasher) *PasswordBuilder {
+	return &PasswordBuilder{h: h}
+}
+
+// Write appends p to the password. It implements io.Writer, and never returns an error.
+func (b *PasswordBuilder) Write(p []byte) (int, error) {
+	if len(b.buf)+len(p) > cap(b.buf) {
+		// grow the buffer manually, so the old one can be zeroed, rather
+		// than leaving a copy of the password behind.
+		buf := make([]byte, len(b.buf), 2*cap(b.buf)+len(p))
+		copy(buf, b.buf)
+		wipe(b.buf)
+		b.buf = buf
+	}
+
+	b.buf = append(b.buf, p...)
+
+	return len(p), nil
+}
+
+// Len returns the number of bytes written to the password.
+func (b *PasswordBuilder) Len() int {
+	return len(b.buf)
+}
+
+// Hash hashes the password written so far, in the same way as Hasher.Hash.
+func (b *PasswordBuilder) Hash() ([]byte, error) {
+	return b.h.Hash(b.buf)
+}
+
+// Verify verifies the password written so far against the hash, in the same way as Hasher.Verify.
+func (b *PasswordBuilder) Verify(hash []byte) bool {
+	return b.h.Verify(b.buf, hash)
+}
+
+// Wipe zeroes the password written so far, and resets the builder, so it can be reused.
+func (b *PasswordBuilder) Wipe() {
+	wipe(b.buf[:cap(b.buf)])
+	b.buf = b.buf[:0]
+}
+
+// zeroes the given buffer.
+func wipe(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
@@ -0,0 +1,46 @@
+package hasher
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPasswordBuilder(t *testing.T) {
+	b := NewPasswordBuilder(defaultHasher)
+	fmt.Fprint(b, "My")
+	b.Write([]byte("Test"))
+	b.Write([]byte("Password"))
+
+	hash, err := b.Hash()
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if !Verify([]byte("MyTestPassword"), hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	if !b.Verify(mustHash(t, []byte("MyTestPassword"))) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Wipe", func(t *testing.T) {
+		buf := b.buf[:cap(b.buf)]
+		b.Wipe()
+
+		if b.Len() != 0 {
+			t.Errorf("expected a length of 0, but got %d", b.Len())
+		}
+
+		for i, v := range buf {
+			if v != 0 {
+				t.Errorf("expected byte %d to be wiped but got %d", i, v)
+			}
+		}
+
+		if b.Verify(hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package hasher
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// verifyCache is an LRU cache of verification results, bounded by both size and
+// age. Entries are keyed by a HMAC of the password and hash, using a random key
+// generated when the cache is created, so neither the plaintext password nor a
+// digest which can be attacked offline is ever stored.
+type verifyCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	key   []byte
+	ll    *list.List
+	items map[[sha256.Size]byte]*list.Element
+	now   func() time.Time
+}
+
+// cacheEntry is the value of a verifyCache list element.
+type cacheEntry struct {
+	digest  [sha256.Size]byte
+	ok      bool
+	expires time.Time
+}
+
+// returns a new verifyCache, with a random HMAC key.
+func newVerifyCache(size int, ttl time.Duration) (*verifyCache, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return &verifyCache{
+		size:  size,
+		ttl:   ttl,
+		key:   key,
+		ll:    list.New(),
+		items: make(map[[sha256.Size]byte]*list.Element, size),
+		now:   time.Now,
+	}, nil
+}
+
+// returns the cache key for the given password and hash.
+func (c *verifyCache) digest(pwd, hash []byte) (d [sha256.Size]byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(pwd)))
+
+	// the password is length-prefixed, so the boundary between it
+	// and the hash is unambiguous.
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(l[:])
+	mac.Write(pwd)
+	mac.Write(hash)
+	copy(d[:], mac.Sum(nil))
+
+	return d
+}
+
+// returns the cached result for the digest, and a flag which
+// determines whether or not an unexpired result was found.
+func (c *verifyCache) get(d [sha256.Size]byte) (ok, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, found := c.items[d]
+	if !found {
+		return false, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, d)
+		return false, false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return entry.ok, true
+}
+
+// caches the result for the digest, evicting the least recently used
+// entry if the cache is full.
+func (c *verifyCache) put(d [sha256.Size]byte, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+
+	if el, found := c.items[d]; found {
+		entry := el.Value.(*cacheEntry)
+		entry.ok = ok
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	if c.ll.Len() >= c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).digest)
+	}
+
+	c.items[d] = c.ll.PushFront(&cacheEntry{
+		digest:  d,
+		ok:      ok,
+		expires: expires,
+	})
+}
@@ -0,0 +1,100 @@
+package hasher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyCache(t *testing.T) {
+	cache, err := newVerifyCache(2, time.Minute)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	a := cache.digest([]byte("a"), []byte("hash"))
+	b := cache.digest([]byte("b"), []byte("hash"))
+	c := cache.digest([]byte("c"), []byte("hash"))
+
+	t.Run("Digest", func(t *testing.T) {
+		// the boundary between the password and hash is unambiguous.
+		if cache.digest([]byte("ab"), []byte("c")) == cache.digest([]byte("a"), []byte("bc")) {
+			t.Errorf("expected digests to differ")
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		cache.put(a, true)
+		cache.put(b, false)
+
+		if ok, found := cache.get(a); !found || !ok {
+			t.Errorf("expected a cached valid result")
+		}
+
+		if ok, found := cache.get(b); !found || ok {
+			t.Errorf("expected a cached invalid result")
+		}
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		// b was used most recently, so a is evicted.
+		cache.put(c, true)
+
+		if _, found := cache.get(a); found {
+			t.Errorf("expected the result to be evicted")
+		}
+
+		if _, found := cache.get(b); !found {
+			t.Errorf("expected a cached result")
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		if _, found := cache.get(c); found {
+			t.Errorf("expected the result to have expired")
+		}
+	})
+}
+
+func TestWithVerifyCache(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithVerifyCache(10, time.Minute))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, _ := h.Hash(pwd)
+
+	for i := 0; i < 2; i++ {
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if h.Verify([]byte("NotMyPassword"), hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+	}
+
+	if l := h.(*hasher).cache.ll.Len(); l != 2 {
+		t.Errorf("expected 2 cached results, but got %d", l)
+	}
+
+	t.Run("Invalid Cache", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithVerifyCache(0, time.Minute))
+		if err != ErrInvalidVerifyCache {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidVerifyCache, err)
+		}
+
+		_, err = New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithVerifyCache(10, 0))
+		if err != ErrInvalidVerifyCache {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidVerifyCache, err)
+		}
+	})
+}
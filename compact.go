@@ -0,0 +1,137 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// CompactHeaderVersion is the version of a compact variant of the HeaderVersion format,
+// which has the same fields and flags, but encodes each numeric value as a varint, rather
+// than a fixed number of bytes, see WithOutputFormatVersion. Typical headers shrink from
+// 15 bytes to 8, at the cost of a header size which varies with its values.
+const CompactHeaderVersion = 3
+
+// compactHeaderPrefix is the number of bytes preceding the varints of a compact
+// header, which are the format magic, the version and a flags byte.
+const compactHeaderPrefix = 3
+
+// maxCompactHeaderLen is the number of bytes used by a compact header without
+// optional values, when each value takes the most bytes it can.
+const maxCompactHeaderLen = compactHeaderPrefix + 3*maxUvarintLen32
+
+// maxUvarintLen32 is the number of bytes a uvarint takes to encode math.MaxUint32.
+const maxUvarintLen32 = 5
+
+// returns the number of bytes needed to write the compact header.
+func (hdr header) compactLen() int {
+	size := compactHeaderPrefix + uvarintLen(hdr.hashKey) + uvarintLen(hdr.iterCnt) + uvarintLen(hdr.saltLen)
+	if hdr.flags&flagPreHash != 0 {
+		size += uvarintLen(hdr.preHash)
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		var buf [binary.MaxVarintLen64]byte
+		size += binary.PutVarint(buf[:], hdr.created)
+	}
+
+	if hdr.flags&flagKeyLen != 0 {
+		size += uvarintLen(hdr.keyLen)
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		size += uvarintLen(hdr.outerHash)
+	}
+
+	return size
+}
+
+// writes the compact header to the start of buf, returning the number of bytes written.
+//
+// The layout is the same as a version 2 header, except every value following the flags
+// is a varint, as encoded by encoding/binary: the creation time is signed, using zig-zag
+// encoding, and every other value is unsigned.
+func writeCompactHeader(buf []byte, hdr header) int {
+	buf[0] = formatMagic
+	buf[1] = byte(hdr.version)
+	buf[2] = hdr.flags
+	offset := compactHeaderPrefix
+
+	offset += binary.PutUvarint(buf[offset:], uint64(hdr.hashKey))
+	offset += binary.PutUvarint(buf[offset:], uint64(hdr.iterCnt))
+	offset += binary.PutUvarint(buf[offset:], uint64(hdr.saltLen))
+
+	if hdr.flags&flagPreHash != 0 {
+		offset += binary.PutUvarint(buf[offset:], uint64(hdr.preHash))
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		offset += binary.PutVarint(buf[offset:], hdr.created)
+	}
+
+	if hdr.flags&flagKeyLen != 0 {
+		offset += binary.PutUvarint(buf[offset:], uint64(hdr.keyLen))
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		offset += binary.PutUvarint(buf[offset:], uint64(hdr.outerHash))
+	}
+
+	return offset
+}
+
+// scans the values of a compact header, following its flags, into hdr, setting its size.
+// Returns ErrInvalidFormat if a value is truncated, exceeds 32 bits, or isn't encoded in
+// the fewest bytes possible, so each header has exactly one encoding.
+func scanCompactHeader(buf []byte, hdr *header) error {
+	offset := compactHeaderPrefix
+
+	scan := func(v *int) bool {
+		x, n := binary.Uvarint(buf[offset:])
+		if n <= 0 || x > math.MaxUint32 || n != uvarintLen(int(x)) {
+			return false
+		}
+
+		*v = int(x)
+		offset += n
+
+		return true
+	}
+
+	if !scan(&hdr.hashKey) || !scan(&hdr.iterCnt) || !scan(&hdr.saltLen) {
+		return ErrInvalidFormat
+	}
+
+	if hdr.flags&flagPreHash != 0 && !scan(&hdr.preHash) {
+		return ErrInvalidFormat
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		x, n := binary.Varint(buf[offset:])
+
+		var canonical [binary.MaxVarintLen64]byte
+		if n <= 0 || n != binary.PutVarint(canonical[:], x) {
+			return ErrInvalidFormat
+		}
+
+		hdr.created = x
+		offset += n
+	}
+
+	if hdr.flags&flagKeyLen != 0 && !scan(&hdr.keyLen) {
+		return ErrInvalidFormat
+	}
+
+	if hdr.flags&flagOuterHash != 0 && !scan(&hdr.outerHash) {
+		return ErrInvalidFormat
+	}
+
+	hdr.size = offset
+
+	return nil
+}
+
+// returns the number of bytes the value takes to encode as a uvarint.
+func uvarintLen(v int) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], uint64(v))
+}
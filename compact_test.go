@@ -0,0 +1,144 @@
+package hasher
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCompactHeader(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithOutputFormatVersion(CompactHeaderVersion))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	hash := mustHashWith(t, h, pwd)
+
+	if hash[1] != CompactHeaderVersion {
+		t.Errorf("expected version '%d' but got '%d'", CompactHeaderVersion, hash[1])
+	}
+
+	// the magic, version and flags, then 1 byte for the hash key and salt
+	// length each, and 2 bytes for the iteration count.
+	if size := len(hash) - DefaultSaltSize/8 - DefaultKeySize/8; size != 7 {
+		t.Errorf("expected a header of '%d' bytes but got '%d'", 7, size)
+	}
+
+	if len(hash) > OutputLenVersion(CompactHeaderVersion, DefaultSaltSize, DefaultKeySize) {
+		t.Errorf("expected the hash to be at most OutputLenVersion bytes")
+	}
+
+	if !h.Verify(pwd, hash) || !Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	if h.Verify([]byte("wrong"), hash) {
+		t.Errorf("expected hash to be invalid")
+	}
+
+	t.Run("Options", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512,
+			WithOutputFormatVersion(CompactHeaderVersion),
+			WithPreHash(HashSHA256),
+			WithTimestamp(true),
+			WithKeyLengthInHeader(true),
+			WithOuterHash(HashSHA512),
+		)
+
+		hash := mustHashWith(t, h, pwd)
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		info, _ := Inspect(hash)
+		if info.Version != CompactHeaderVersion || info.PreHash != HashSHA256 || info.OuterHash != HashSHA512 || info.CreatedAt.IsZero() {
+			t.Errorf("expected the options to be recorded but got '%+v'", info)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		hdr := []byte{formatMagic, CompactHeaderVersion, 0}
+		salt := make([]byte, 16)
+
+		// Trailing is the bytes following the values, which is nothing for a truncated
+		// value, as it must be at the end of the hash.
+		testCases := []struct {
+			Name     string
+			Values   []byte
+			Trailing []byte
+		}{
+			{Name: "Truncated", Values: []byte{0x01, 0xE8}},
+			{Name: "Overlong", Values: []byte{0x81, 0x00, 0xE8, 0x07, 0x10}, Trailing: salt},
+			{Name: "Overlong Zero", Values: []byte{0x01, 0xE8, 0x07, 0x90, 0x00}, Trailing: salt},
+			{Name: "Exceeds 32 Bits", Values: []byte{0x01, 0x80, 0x80, 0x80, 0x80, 0x10, 0x10}, Trailing: salt},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				hash := append(append(append([]byte{}, hdr...), tc.Values...), tc.Trailing...)
+
+				if _, err := scanHeader(hash); err != ErrInvalidFormat {
+					t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+				}
+			})
+		}
+	})
+}
+
+func TestCompactHeaderRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, value := range headerValueSamples() {
+		expected := header{
+			version:   CompactHeaderVersion,
+			flags:     flagPreHash | flagTimestamp | flagKeyLen | flagOuterHash,
+			hashKey:   int(value),
+			iterCnt:   int(value),
+			saltLen:   1,
+			preHash:   int(value),
+			created:   r.Int63() - r.Int63(),
+			keyLen:    1,
+			outerHash: int(value),
+		}
+
+		buf := make([]byte, expected.len()+expected.saltLen+expected.keyLen)
+		n := writeHeader(buf, expected)
+
+		hdr, err := scanHeader(buf)
+		if err != nil {
+			t.Fatalf("%d: didn't expect to get an error: %v", value, err)
+		}
+
+		expected.size = n
+		if hdr != expected {
+			t.Errorf("%d: expected '%+v' but got '%+v'", value, expected, hdr)
+		}
+	}
+}
+
+func BenchmarkHeaderSize(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+
+	benchmarks := []struct {
+		Name    string
+		Version int
+	}{
+		{Name: "Version 1", Version: 1},
+		{Name: "Version 2", Version: HeaderVersion},
+		{Name: "Compact", Version: CompactHeaderVersion},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			h, _ := New(100000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithOutputFormatVersion(bm.Version))
+
+			var hash []byte
+			for i := 0; i < b.N; i++ {
+				hash, _ = h.Hash(pwd)
+			}
+
+			hdr, _ := scanHeader(hash)
+			b.ReportMetric(float64(hdr.size), "header-bytes")
+		})
+	}
+}
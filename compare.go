@@ -0,0 +1,34 @@
+package hasher
+
+import "crypto/subtle"
+
+// ConstantTimeEqual returns true if a and b are equal, comparing them in constant time,
+// so the time taken doesn't reveal how many leading bytes match, as with bytes.Equal.
+// It can be used to compare sub-keys, as well as other secrets, such as tokens.
+//
+// Unlike subtle.ConstantTimeCompare, slices of different lengths don't return early.
+// The shorter slice is compared as if padded with zeros to the length of the longer,
+// and the lengths are compared separately, so the time taken depends only on the
+// longer length. Equal slices of any length, including empty slices, are equal.
+func ConstantTimeEqual(a, b []byte) bool {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+
+	var v byte
+	for i := 0; i < n; i++ {
+		var x, y byte
+		if i < len(a) {
+			x = a[i]
+		}
+
+		if i < len(b) {
+			y = b[i]
+		}
+
+		v |= x ^ y
+	}
+
+	return subtle.ConstantTimeByteEq(v, 0)&subtle.ConstantTimeEq(int32(len(a)), int32(len(b))) == 1
+}
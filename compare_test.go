@@ -0,0 +1,27 @@
+package hasher
+
+import "testing"
+
+func TestConstantTimeEqual(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		A, B     []byte
+		Expected bool
+	}{
+		{"Equal", []byte("token"), []byte("token"), true},
+		{"Different", []byte("token"), []byte("tokem"), false},
+		{"Prefix", []byte("token"), []byte("tok"), false},
+		{"Longer", []byte("tok"), []byte("token"), false},
+		{"Zero Padded", []byte("tok"), []byte("tok\x00\x00"), false},
+		{"Empty", []byte{}, nil, true},
+		{"One Empty", nil, []byte("token"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if ok := ConstantTimeEqual(tc.A, tc.B); ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"time"
+)
+
+// ErrInvalidTarget is returned by AutoConfig when the target duration is not positive.
+var ErrInvalidTarget = errors.New("target duration must be positive")
+
+// autoConfigMinSample is the minimum duration AutoConfig spends deriving a key to
+// measure the speed of the current hardware, so the measurement isn't dominated by noise.
+const autoConfigMinSample = 10 * time.Millisecond
+
+// Config contains the parameters of a Hasher, as given to New.
+type Config struct {
+	IterationCount int
+
+	// SaltSize and KeySize are recognised as number of bits.
+	SaltSize int
+	KeySize  int
+
+	HashKey int
+}
+
+// ValidateConfig validates the config, using the same rules as New. Rather
+// than returning the first problem found, as New does, the returned error
+// joins an error for every invalid field, so they can all be reported at once.
+// Each can be matched using errors.Is, for example, with ErrInvalidSaltSize.
+//
+// Will return nil if the config is valid.
+func ValidateConfig(c Config) error {
+	return errors.Join(c.validate()...)
+}
+
+// MergeConfig returns the base config, with each non-zero field of override in place
+// of the base's, for layering per-tenant overrides on top of a base policy, such as a
+// stronger iteration count for some tenants.
+//
+// As zero is never a valid value for any field, a zero field in override always means
+// it's unset, rather than an intentional zero, so no pointer fields are needed. Fields
+// in override aren't checked, so validate the result, using ValidateConfig, before use.
+func MergeConfig(base, override Config) Config {
+	if override.IterationCount != 0 {
+		base.IterationCount = override.IterationCount
+	}
+
+	if override.SaltSize != 0 {
+		base.SaltSize = override.SaltSize
+	}
+
+	if override.KeySize != 0 {
+		base.KeySize = override.KeySize
+	}
+
+	if override.HashKey != 0 {
+		base.HashKey = override.HashKey
+	}
+
+	return base
+}
+
+// AutoConfig returns a ready-to-use Config, for hashing a password in roughly the
+// target duration on the current hardware. The algorithm is RecommendAlgorithm, the salt
+// size is DefaultSaltSize, and the key size is the algorithm's digest size, as larger keys
+// cost more to derive, without making an attacker's work any harder. The iteration count
+// is calibrated by timing derivations, and is never less than DefaultIterationCount.
+//
+// The result depends on the hardware, and its load at the time, so should be chosen
+// once, then pinned in configuration, rather than calling AutoConfig on every start-up.
+//
+// A non-nil error will be returned if the target is not positive.
+func AutoConfig(target time.Duration) (Config, error) {
+	if target <= 0 {
+		return Config{}, ErrInvalidTarget
+	}
+
+	hashKey := RecommendAlgorithm()
+	hashFunc := alg(hashKey)
+	keyLen := hashFunc().Size()
+	pwd := []byte("adaptive-password-hasher/auto-config")
+	salt := make([]byte, DefaultSaltSize/8)
+
+	// double the sample size until it takes long enough to measure reliably.
+	iterCnt := DefaultIterationCount
+	var elapsed time.Duration
+	for {
+		start := time.Now()
+		deriveKey(pwd, salt, iterCnt, keyLen, hashFunc, nil)
+		elapsed = time.Since(start)
+
+		if elapsed >= autoConfigMinSample || elapsed >= target {
+			break
+		}
+
+		iterCnt *= 2
+	}
+
+	// the iteration count is capped, so it fits in the header on all platforms.
+	scaled := math.Min(float64(iterCnt)*float64(target)/float64(elapsed), math.MaxInt32)
+	if scaled < DefaultIterationCount {
+		scaled = DefaultIterationCount
+	}
+
+	return Config{
+		IterationCount: int(scaled),
+		SaltSize:       DefaultSaltSize,
+		KeySize:        keyLen * 8,
+		HashKey:        hashKey,
+	}, nil
+}
+
+// validates the config, returning an error for every invalid field,
+// in the order New checks them.
+func (c Config) validate() []error {
+	var errs []error
+
+	if c.IterationCount < 1 {
+		errs = append(errs, ErrInvalidIterationCount)
+	}
+
+	if err := checkSize("saltSize", c.SaltSize, ErrInvalidSaltSize); err != nil {
+		errs = append(errs, err)
+	}
+
+	keySizeErr := checkSize("keySize", c.KeySize, ErrInvalidKeySize)
+	if keySizeErr != nil {
+		errs = append(errs, keySizeErr)
+	}
+
+	hashFunc, ok := lookupAlg(c.HashKey)
+	if !ok {
+		errs = append(errs, ErrUnsupportedHashKey)
+	} else if keySizeErr == nil {
+		if err := checkKeyLen(c.KeySize, hashFunc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// NormalizeSizes validates the salt and key sizes, in bits, using the same rules as New,
+// returning them converted to bytes, so sizes can be checked before a hasher is created,
+// such as in a form handler, without repeating the rules. A non-nil *SizeError will be
+// returned, wrapping ErrInvalidSaltSize or ErrInvalidKeySize, if either size is not
+// positive, or not divisible by 8. The salt size is checked first.
+//
+// The key size isn't checked against the limit of any algorithm, see ValidateConfig.
+func NormalizeSizes(saltBits, keyBits int) (saltBytes, keyBytes int, err error) {
+	if err := checkSize("saltSize", saltBits, ErrInvalidSaltSize); err != nil {
+		return 0, 0, err
+	}
+
+	if err := checkSize("keySize", keyBits, ErrInvalidKeySize); err != nil {
+		return 0, 0, err
+	}
+
+	return saltBits / 8, keyBits / 8, nil
+}
+
+// returns a *SizeError wrapping ErrKeyTooLarge if a key of the given size, in bits,
+// can't be derived using pbkdf2 with the hash function, otherwise nil.
+func checkKeyLen(keySize int, hashFunc func() hash.Hash) error {
+	if validKeyLen(keySize/8, hashFunc) {
+		return nil
+	}
+
+	return &SizeError{
+		Field: "keySize",
+		Size:  keySize,
+		Rule:  fmt.Sprintf("exceeds the pbkdf2 limit of %d bits", uint64(maxBlocks)*uint64(hashFunc().Size())*8),
+		Err:   ErrKeyTooLarge,
+	}
+}
+
+// returns a *SizeError wrapping err if the size, in bits, is not positive,
+// or not divisible by 8, otherwise nil.
+func checkSize(field string, size int, err error) error {
+	switch {
+	case size < 1:
+		return &SizeError{Field: field, Size: size, Rule: "is not positive", Err: err}
+	case size%8 != 0:
+		return &SizeError{Field: field, Size: size, Rule: "is not divisible by 8", Err: err}
+	default:
+		return nil
+	}
+}
+
+// SizeError is returned by New and ValidateConfig when a salt or key size is
+// invalid, describing the size given, and the rule it broke. It wraps one of
+// ErrInvalidSaltSize, ErrInvalidKeySize or ErrKeyTooLarge, which can be matched
+// using errors.Is.
+type SizeError struct {
+	// Field is the name of the invalid parameter, either "saltSize" or "keySize".
+	Field string
+
+	// Size is the size given, in bits.
+	Size int
+
+	// Rule describes the constraint which the size broke, such as "is not divisible by 8".
+	Rule string
+
+	Err error
+}
+
+// Error returns a description of the size and rule, such as "saltSize 14 is not divisible by 8".
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("hasher: %s %d %s", e.Field, e.Size, e.Rule)
+}
+
+// Unwrap returns the error wrapped by e.
+func (e *SizeError) Unwrap() error {
+	return e.Err
+}
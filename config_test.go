@@ -0,0 +1,206 @@
+package hasher
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := Config{
+		IterationCount: DefaultIterationCount,
+		SaltSize:       DefaultSaltSize,
+		KeySize:        DefaultKeySize,
+		HashKey:        DefaultHashKey,
+	}
+
+	if err := ValidateConfig(valid); err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	t.Run("All Invalid", func(t *testing.T) {
+		err := ValidateConfig(Config{
+			IterationCount: 0,
+			SaltSize:       14,
+			KeySize:        -1,
+			HashKey:        237,
+		})
+
+		for _, expected := range []error{
+			ErrInvalidIterationCount,
+			ErrInvalidSaltSize,
+			ErrInvalidKeySize,
+			ErrUnsupportedHashKey,
+		} {
+			if !errors.Is(err, expected) {
+				t.Errorf("expected '%v' to include '%v'", err, expected)
+			}
+		}
+	})
+
+	t.Run("Single Invalid", func(t *testing.T) {
+		c := valid
+		c.SaltSize = 14
+
+		err := ValidateConfig(c)
+		if !errors.Is(err, ErrInvalidSaltSize) {
+			t.Errorf("expected '%v' to include '%v'", err, ErrInvalidSaltSize)
+		}
+
+		if errors.Is(err, ErrInvalidKeySize) {
+			t.Errorf("didn't expect '%v' to include '%v'", err, ErrInvalidKeySize)
+		}
+	})
+}
+
+func TestMergeConfig(t *testing.T) {
+	base := Config{
+		IterationCount: DefaultIterationCount,
+		SaltSize:       DefaultSaltSize,
+		KeySize:        DefaultKeySize,
+		HashKey:        DefaultHashKey,
+	}
+
+	testCases := []struct {
+		Name     string
+		Override Config
+		Expected Config
+	}{
+		{Name: "Empty", Override: Config{}, Expected: base},
+		{
+			Name:     "Partial",
+			Override: Config{IterationCount: 100000, HashKey: HashSHA512},
+			Expected: Config{IterationCount: 100000, SaltSize: DefaultSaltSize, KeySize: DefaultKeySize, HashKey: HashSHA512},
+		},
+		{
+			Name:     "Full",
+			Override: Config{IterationCount: 5000, SaltSize: 256, KeySize: 512, HashKey: HashSHA512},
+			Expected: Config{IterationCount: 5000, SaltSize: 256, KeySize: 512, HashKey: HashSHA512},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			c := MergeConfig(base, tc.Override)
+			if c != tc.Expected {
+				t.Errorf("expected '%+v' but got '%+v'", tc.Expected, c)
+			}
+
+			if err := ValidateConfig(c); err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+			}
+		})
+	}
+
+	t.Run("Invalid Override", func(t *testing.T) {
+		c := MergeConfig(base, Config{SaltSize: 14})
+		if err := ValidateConfig(c); !errors.Is(err, ErrInvalidSaltSize) {
+			t.Errorf("expected '%v' to include '%v'", err, ErrInvalidSaltSize)
+		}
+	})
+}
+
+func TestNormalizeSizes(t *testing.T) {
+	saltBytes, keyBytes, err := NormalizeSizes(DefaultSaltSize, DefaultKeySize)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	if saltBytes != DefaultSaltSize/8 || keyBytes != DefaultKeySize/8 {
+		t.Errorf("expected (%d, %d) but got (%d, %d)", DefaultSaltSize/8, DefaultKeySize/8, saltBytes, keyBytes)
+	}
+
+	testCases := []struct {
+		Name      string
+		SaltBits  int
+		KeyBits   int
+		Expected  error
+		ErrString string
+	}{
+		{"Salt Not Divisible", 14, DefaultKeySize, ErrInvalidSaltSize, "hasher: saltSize 14 is not divisible by 8"},
+		{"Salt Not Positive", 0, DefaultKeySize, ErrInvalidSaltSize, "hasher: saltSize 0 is not positive"},
+		{"Key Not Divisible", DefaultSaltSize, 250, ErrInvalidKeySize, "hasher: keySize 250 is not divisible by 8"},
+		{"Key Not Positive", DefaultSaltSize, -8, ErrInvalidKeySize, "hasher: keySize -8 is not positive"},
+		{"Both", 14, 250, ErrInvalidSaltSize, "hasher: saltSize 14 is not divisible by 8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			saltBytes, keyBytes, err := NormalizeSizes(tc.SaltBits, tc.KeyBits)
+			if !errors.Is(err, tc.Expected) {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+			}
+
+			if err != nil && err.Error() != tc.ErrString {
+				t.Errorf("expected '%s' but got '%s'", tc.ErrString, err.Error())
+			}
+
+			if saltBytes != 0 || keyBytes != 0 {
+				t.Errorf("expected zero sizes but got (%d, %d)", saltBytes, keyBytes)
+			}
+
+			// New applies the same rules.
+			if _, err := New(DefaultIterationCount, tc.SaltBits, tc.KeyBits, DefaultHashKey); !errors.Is(err, tc.Expected) {
+				t.Errorf("expected New to return '%v' but got '%v'", tc.Expected, err)
+			}
+		})
+	}
+}
+
+func TestSizeError(t *testing.T) {
+	_, err := New(DefaultIterationCount, 14, DefaultKeySize, DefaultHashKey)
+
+	var sizeErr *SizeError
+	if !errors.As(err, &sizeErr) {
+		t.Errorf("expected a *SizeError but got '%v'", err)
+		return
+	}
+
+	if sizeErr.Field != "saltSize" || sizeErr.Size != 14 {
+		t.Errorf("expected 'saltSize' of 14 but got '%s' of %d", sizeErr.Field, sizeErr.Size)
+	}
+
+	expected := "hasher: saltSize 14 is not divisible by 8"
+	if err.Error() != expected {
+		t.Errorf("expected '%s' but got '%s'", expected, err.Error())
+	}
+
+	if !errors.Is(err, ErrInvalidSaltSize) {
+		t.Errorf("expected '%v' to match '%v'", err, ErrInvalidSaltSize)
+	}
+
+	t.Run("Not Positive", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, -8, DefaultHashKey)
+
+		expected := "hasher: keySize -8 is not positive"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected '%s' but got '%v'", expected, err)
+		}
+	})
+}
+
+func TestAutoConfig(t *testing.T) {
+	c, err := AutoConfig(20 * time.Millisecond)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if err := ValidateConfig(c); err != nil {
+		t.Errorf("expected a valid config but got '%v'", err)
+	}
+
+	if c.HashKey != RecommendAlgorithm() {
+		t.Errorf("expected hash key %d but got %d", RecommendAlgorithm(), c.HashKey)
+	}
+
+	if c.IterationCount < DefaultIterationCount {
+		t.Errorf("expected at least %d iterations but got %d", DefaultIterationCount, c.IterationCount)
+	}
+
+	t.Run("Invalid Target", func(t *testing.T) {
+		if _, err := AutoConfig(0); err != ErrInvalidTarget {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidTarget, err)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Errors returned when verifying delimited strings.
+var (
+	ErrInvalidDelimited = errors.New("string does not match the delimited layout")
+	ErrInvalidLayout    = errors.New("delimited layout is invalid")
+)
+
+// Encoding is the encoding of binary data, such as a salt or sub-key, in a textual format.
+type Encoding int
+
+const (
+	// EncodingHex is hexadecimal encoding, in either case.
+	EncodingHex Encoding = iota
+
+	// EncodingBase64 is base64 encoding, using either the standard or
+	// URL-safe alphabet, with or without padding.
+	EncodingBase64
+)
+
+// decodes the string using the encoding.
+func (e Encoding) decode(s string) ([]byte, error) {
+	switch e {
+	case EncodingHex:
+		return hex.DecodeString(s)
+	case EncodingBase64:
+		return decodeBase64(s)
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %d", e)
+	}
+}
+
+// DelimitedField is a field of a delimited string, see DelimitedLayout.
+type DelimitedField int
+
+const (
+	// FieldAlgorithm is the name of the hash algorithm, such as "sha256".
+	FieldAlgorithm DelimitedField = iota + 1
+
+	// FieldIterations is the iteration count, in decimal.
+	FieldIterations
+
+	// FieldSalt is the salt, encoded using the layout's SaltEncoding.
+	FieldSalt
+
+	// FieldKey is the sub-key, encoded using the layout's KeyEncoding.
+	FieldKey
+)
+
+// DelimitedLayout describes a textual format, where the fields of a pbkdf2 hash are
+// separated by a delimiter, such as "sha256|1000|<salt>|<key>", for VerifyDelimited.
+type DelimitedLayout struct {
+	// Delimiter separates each field, and must not be empty.
+	Delimiter string
+
+	// Fields is the order of the fields in the string. Each field
+	// must be present exactly once.
+	Fields []DelimitedField
+
+	// SaltEncoding and KeyEncoding are the encodings of the salt and sub-key.
+	SaltEncoding Encoding
+	KeyEncoding  Encoding
+}
+
+// validates the layout, returning ErrInvalidLayout if it's invalid.
+func (l DelimitedLayout) validate() error {
+	if l.Delimiter == "" {
+		return fmt.Errorf("%w: the delimiter is empty", ErrInvalidLayout)
+	}
+
+	seen := make(map[DelimitedField]bool, len(l.Fields))
+	for _, f := range l.Fields {
+		if f < FieldAlgorithm || f > FieldKey || seen[f] {
+			return fmt.Errorf("%w: field %d is unknown, or repeated", ErrInvalidLayout, f)
+		}
+
+		seen[f] = true
+	}
+
+	if len(seen) != 4 {
+		return fmt.Errorf("%w: expected 4 fields, but got %d", ErrInvalidLayout, len(seen))
+	}
+
+	return nil
+}
+
+// VerifyDelimited verifies the password against a pbkdf2 hash in a textual format
+// described by the layout, where each field is separated by a delimiter, returning
+// a flag which determines whether or not the password matches the hash. This is an
+// adapter for formats used by other systems, for example, "sha256|1000|<salt>|<key>",
+// with hex fields, is read using the layout:
+//
+//	DelimitedLayout{
+//		Delimiter: "|",
+//		Fields:    []DelimitedField{FieldAlgorithm, FieldIterations, FieldSalt, FieldKey},
+//	}
+//
+// The algorithm must be the name of a supported algorithm, "sha256" or "sha512".
+//
+// ErrInvalidLayout will be returned if the layout is invalid, or an error wrapping
+// ErrInvalidDelimited, describing the problem, if the string doesn't match the layout.
+// ErrUnsupportedHashKey will be returned if the algorithm is not recognised.
+func VerifyDelimited(pwd []byte, s string, layout DelimitedLayout) (bool, error) {
+	if err := layout.validate(); err != nil {
+		return false, err
+	}
+
+	fields := strings.Split(s, layout.Delimiter)
+	if len(fields) != len(layout.Fields) {
+		return false, fmt.Errorf("%w: expected %d fields, but got %d", ErrInvalidDelimited, len(layout.Fields), len(fields))
+	}
+
+	var (
+		hashKey   int
+		iterCnt   int
+		salt, key []byte
+		err       error
+	)
+
+	for i, f := range layout.Fields {
+		switch f {
+		case FieldAlgorithm:
+			var ok bool
+			if hashKey, ok = lookupAlgName(fields[i]); !ok {
+				return false, fmt.Errorf("%w: %q", ErrUnsupportedHashKey, fields[i])
+			}
+		case FieldIterations:
+			if iterCnt, err = parseIterations(fields[i]); err != nil {
+				return false, fmt.Errorf("%w: field %d: invalid iteration count %q", ErrInvalidDelimited, i, fields[i])
+			}
+		case FieldSalt:
+			if salt, err = layout.SaltEncoding.decode(fields[i]); err != nil {
+				return false, fmt.Errorf("%w: field %d: invalid salt: %v", ErrInvalidDelimited, i, err)
+			}
+		case FieldKey:
+			if key, err = layout.KeyEncoding.decode(fields[i]); err != nil {
+				return false, fmt.Errorf("%w: field %d: invalid key: %v", ErrInvalidDelimited, i, err)
+			}
+
+			if len(key) < 1 {
+				return false, fmt.Errorf("%w: field %d: the key is empty", ErrInvalidDelimited, i)
+			}
+		}
+	}
+
+	actual := pbkdf2.Key(pwd, salt, iterCnt, len(key), alg(hashKey))
+
+	return ConstantTimeEqual(actual, key), nil
+}
+
+// VerifyExternalParams verifies the password against a pbkdf2 hash stored as a salt and
+// sub-key, separated by sep, such as "hex(salt):hex(key)", where the iteration count and
+// algorithm aren't stored alongside, but held elsewhere, such as in application config.
+// Both the salt and sub-key are encoded using enc.
+//
+// ErrInvalidIterationCount or ErrUnsupportedHashKey will be returned if the parameters
+// are invalid, ErrInvalidLayout if sep is empty, or an error wrapping ErrInvalidDelimited,
+// describing the problem, if the string is malformed.
+func VerifyExternalParams(pwd []byte, s string, sep string, iter, hashKey int, enc Encoding) (bool, error) {
+	if iter < 1 {
+		return false, ErrInvalidIterationCount
+	}
+
+	hashFunc, ok := lookupAlg(hashKey)
+	if !ok {
+		return false, ErrUnsupportedHashKey
+	}
+
+	if sep == "" {
+		return false, fmt.Errorf("%w: the separator is empty", ErrInvalidLayout)
+	}
+
+	fields := strings.Split(s, sep)
+	if len(fields) != 2 {
+		return false, fmt.Errorf("%w: expected 2 fields, but got %d", ErrInvalidDelimited, len(fields))
+	}
+
+	salt, err := enc.decode(fields[0])
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid salt: %v", ErrInvalidDelimited, err)
+	}
+
+	key, err := enc.decode(fields[1])
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid key: %v", ErrInvalidDelimited, err)
+	}
+
+	if len(key) < 1 {
+		return false, fmt.Errorf("%w: the key is empty", ErrInvalidDelimited)
+	}
+
+	actual := pbkdf2.Key(pwd, salt, iter, len(key), hashFunc)
+
+	return ConstantTimeEqual(actual, key), nil
+}
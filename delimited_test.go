@@ -0,0 +1,140 @@
+package hasher
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyDelimited(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	pipes := DelimitedLayout{
+		Delimiter: "|",
+		Fields:    []DelimitedField{FieldAlgorithm, FieldIterations, FieldSalt, FieldKey},
+	}
+
+	// vectors generated using Python's hashlib.pbkdf2_hmac.
+	testCases := []struct {
+		Name   string
+		S      string
+		Layout DelimitedLayout
+	}{
+		{
+			Name:   "Pipes",
+			S:      "sha512|2000|00112233|324b47b980c99029426e53542ee74b05acee07d23f432cc177f9a4c55d19ab59ba0568260e5f3426d7ed4c9cdfaacc4b6c98b140b5d79b2c11b50a782e4679d9",
+			Layout: pipes,
+		},
+		{
+			Name: "Reordered Base64",
+			S:    "TXlUZXN0U2FsdA::Vb+IWDvGOA034SGwEPCWCtgINWA/HirnmCuRIaj2NUY=::1000::sha256",
+			Layout: DelimitedLayout{
+				Delimiter:    "::",
+				Fields:       []DelimitedField{FieldSalt, FieldKey, FieldIterations, FieldAlgorithm},
+				SaltEncoding: EncodingBase64,
+				KeyEncoding:  EncodingBase64,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := VerifyDelimited(pwd, tc.S, tc.Layout)
+			if !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+			}
+
+			ok, err = VerifyDelimited([]byte("wrong"), tc.S, tc.Layout)
+			if ok || err != nil {
+				t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+			}
+		})
+	}
+
+	t.Run("Invalid String", func(t *testing.T) {
+		for _, s := range []string{
+			"sha256|1000|00112233",
+			"sha256|1000|00112233|abcd|extra",
+			"sha256|0|00112233|abcd",
+			"sha256|x|00112233|abcd",
+			"sha256|1000|0011223|abcd",
+			"sha256|1000|00112233|zz",
+			"sha256|1000|00112233|",
+		} {
+			if _, err := VerifyDelimited(pwd, s, pipes); !errors.Is(err, ErrInvalidDelimited) {
+				t.Errorf("%q: expected '%v' but got '%v'", s, ErrInvalidDelimited, err)
+			}
+		}
+	})
+
+	t.Run("Unsupported Algorithm", func(t *testing.T) {
+		if _, err := VerifyDelimited(pwd, "md5|1000|00112233|abcd", pipes); !errors.Is(err, ErrUnsupportedHashKey) {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedHashKey, err)
+		}
+	})
+
+	t.Run("Invalid Layout", func(t *testing.T) {
+		layouts := []DelimitedLayout{
+			{Delimiter: "", Fields: pipes.Fields},
+			{Delimiter: "|", Fields: []DelimitedField{FieldAlgorithm, FieldIterations, FieldSalt}},
+			{Delimiter: "|", Fields: []DelimitedField{FieldAlgorithm, FieldSalt, FieldSalt, FieldKey}},
+			{Delimiter: "|", Fields: []DelimitedField{FieldAlgorithm, FieldIterations, FieldSalt, 9}},
+		}
+
+		for i, layout := range layouts {
+			if _, err := VerifyDelimited(pwd, "sha256|1000|00112233|abcd", layout); !errors.Is(err, ErrInvalidLayout) {
+				t.Errorf("%d: expected '%v' but got '%v'", i, ErrInvalidLayout, err)
+			}
+		}
+	})
+}
+
+func TestVerifyExternalParams(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	// vectors generated using Python's hashlib.pbkdf2_hmac.
+	testCases := []struct {
+		Name string
+		S    string
+		Sep  string
+		Enc  Encoding
+	}{
+		{Name: "Hex", S: "a1b2c3d4e5f60718:05e15adf31f9a159c21e97c1df697f9f39f1be12d9d8f9f2d304a0ddf9bf51db", Sep: ":", Enc: EncodingHex},
+		{Name: "Base64", S: "obLD1OX2Bxg=$BeFa3zH5oVnCHpfB32l/nznxvhLZ2Pny0wSg3fm/Uds=", Sep: "$", Enc: EncodingBase64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := VerifyExternalParams(pwd, tc.S, tc.Sep, 5000, HashSHA256, tc.Enc)
+			if !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+			}
+
+			ok, err = VerifyExternalParams(pwd, tc.S, tc.Sep, 4999, HashSHA256, tc.Enc)
+			if ok || err != nil {
+				t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+			}
+		})
+	}
+
+	t.Run("Invalid String", func(t *testing.T) {
+		for _, s := range []string{"a1b2", "a1b2:c3d4:e5f6", "a1b:c3d4", "a1b2:zz", "a1b2:"} {
+			if _, err := VerifyExternalParams(pwd, s, ":", 5000, HashSHA256, EncodingHex); !errors.Is(err, ErrInvalidDelimited) {
+				t.Errorf("%q: expected '%v' but got '%v'", s, ErrInvalidDelimited, err)
+			}
+		}
+	})
+
+	t.Run("Invalid Params", func(t *testing.T) {
+		s := testCases[0].S
+		if _, err := VerifyExternalParams(pwd, s, ":", 0, HashSHA256, EncodingHex); err != ErrInvalidIterationCount {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidIterationCount, err)
+		}
+
+		if _, err := VerifyExternalParams(pwd, s, ":", 5000, 42, EncodingHex); err != ErrUnsupportedHashKey {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedHashKey, err)
+		}
+
+		if _, err := VerifyExternalParams(pwd, s, "", 5000, HashSHA256, EncodingHex); !errors.Is(err, ErrInvalidLayout) {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidLayout, err)
+		}
+	})
+}
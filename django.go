@@ -0,0 +1,120 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrInvalidDjango is returned when a string is not in Django's pbkdf2 format.
+var ErrInvalidDjango = errors.New("string is not in the django pbkdf2 format")
+
+const (
+	// DjangoIterationCount is the iteration count used by HashDjango,
+	// which is the default of Django 5.1's PBKDF2PasswordHasher.
+	DjangoIterationCount = 870000
+
+	// djangoSaltLen is the length of salts generated by HashDjango, which
+	// Django chooses so the salt carries at least 128 bits of entropy.
+	djangoSaltLen = 22
+
+	// djangoSaltAlphabet is the set of characters used in Django's salts.
+	djangoSaltAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// djangoAlgorithms maps the algorithm names used by Django's pbkdf2 hashers to their hash functions.
+var djangoAlgorithms = map[string]func() hash.Hash{
+	"pbkdf2_sha256": sha256.New,
+	"pbkdf2_sha1":   sha1.New,
+}
+
+// HashDjango hashes the given password in the format used by Django's default
+// PBKDF2PasswordHasher, so the hash can be verified by a Django application:
+//
+//	pbkdf2_sha256$<iterations>$<salt>$<hash>
+//
+// Unlike this library's own format, the salt is stored as text, which Django generates
+// as 22 random alphanumeric characters, and is used as-is as the pbkdf2 salt. The hash is
+// the SHA256-sized derived key, encoded using padded, standard base64. The iteration
+// count is DjangoIterationCount.
+//
+// A non-nil error will be returned if a salt could not be generated.
+func HashDjango(pwd []byte) (string, error) {
+	salt, err := djangoSalt()
+	if err != nil {
+		return "", fmt.Errorf("hasher: failed to generate salt: %w", err)
+	}
+
+	key := pbkdf2.Key(pwd, []byte(salt), DjangoIterationCount, sha256.Size, sha256.New)
+
+	return fmt.Sprintf("pbkdf2_sha256$%d$%s$%s", DjangoIterationCount, salt, base64.StdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyDjango verifies the password against a hash produced by Django's
+// PBKDF2PasswordHasher, or PBKDF2SHA1PasswordHasher, returning a flag which determines
+// whether or not the password matches the hash.
+//
+// The string must be in the format "<algorithm>$<iterations>$<salt>$<hash>", where the
+// algorithm is either "pbkdf2_sha256" or "pbkdf2_sha1", see HashDjango.
+//
+// A non-nil error will be returned if the string is not in Django's format, or
+// ErrUnsupportedScheme if the algorithm is not recognised.
+func VerifyDjango(pwd []byte, s string) (bool, error) {
+	fields := strings.Split(s, "$")
+
+	// other hashers have a different number of fields, so
+	// the algorithm is checked first, as with VerifyMCF.
+	hashFunc, ok := djangoAlgorithms[fields[0]]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedScheme, fields[0])
+	}
+
+	if len(fields) != 4 {
+		return false, ErrInvalidDjango
+	}
+
+	iterCnt, err := parseIterations(fields[1])
+	if err != nil {
+		return false, ErrInvalidDjango
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(fields[3])
+	if err != nil || len(expected) < 1 {
+		return false, ErrInvalidDjango
+	}
+
+	actual := pbkdf2.Key(pwd, []byte(fields[2]), iterCnt, len(expected), hashFunc)
+
+	return ConstantTimeEqual(actual, expected), nil
+}
+
+// returns a random salt in the same format as Django's, generated using crypto/rand.
+func djangoSalt() (string, error) {
+	// bytes at or above the largest multiple of the alphabet's length are
+	// discarded, so characters are selected without bias.
+	const limit = 256 - 256%len(djangoSaltAlphabet)
+
+	salt := make([]byte, 0, djangoSaltLen)
+	buf := make([]byte, djangoSaltLen)
+
+	for len(salt) < djangoSaltLen {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+
+		for _, b := range buf {
+			if int(b) < limit && len(salt) < djangoSaltLen {
+				salt = append(salt, djangoSaltAlphabet[int(b)%len(djangoSaltAlphabet)])
+			}
+		}
+	}
+
+	return string(salt), nil
+}
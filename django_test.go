@@ -0,0 +1,82 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDjango(t *testing.T) {
+	pwd := []byte("lètmein")
+	hashes := map[string]string{
+		// the SHA256 hashes are those Django's own test suite expects from
+		// make_password("lètmein", "seasalt", "pbkdf2_sha256"), in Django 1.4, 3.2
+		// and 5.1, whose default iteration counts differ.
+		"SHA256 Django 1.4": "pbkdf2_sha256$10000$seasalt$CWWFdHOWwPnki7HvkcqN9iA2T3KLW1cf2uZ5kvArtVY=",
+		"SHA256 Django 3.2": "pbkdf2_sha256$260000$seasalt$YlZ2Vggtqdc61YjArZuoApoBh9JNGYoDRBUGu6tcJQo=",
+		"SHA256 Django 5.1": "pbkdf2_sha256$870000$seasalt$wJSpLMQRQz0Dhj/pFpbyjMj71B2gUYp6HJS5AU+32Ac=",
+
+		// built following Django's PBKDF2SHA1PasswordHasher, with the salt "seasalt".
+		"SHA1": "pbkdf2_sha1$870000$seasalt$UiqYIlBUWaJY1625aFAwcLy17so=",
+	}
+
+	for name, s := range hashes {
+		t.Run(name, func(t *testing.T) {
+			ok, err := VerifyDjango(pwd, s)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+			}
+
+			if !ok {
+				t.Errorf("expected hash to be valid")
+			}
+
+			ok, _ = VerifyDjango([]byte("NotMyPassword"), s)
+			if ok {
+				t.Errorf("expected hash to be invalid")
+			}
+		})
+	}
+
+	t.Run("Unsupported Scheme", func(t *testing.T) {
+		for _, s := range []string{
+			"argon2$argon2id$v=19$m=102400,t=2,p=8$c2FsdA$aGFzaA",
+			"bcrypt_sha256$$2b$12$aGFzaA",
+		} {
+			if _, err := VerifyDjango(pwd, s); !errors.Is(err, ErrUnsupportedScheme) {
+				t.Errorf("expected '%v' but got '%v'", ErrUnsupportedScheme, err)
+			}
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		for _, s := range []string{
+			"pbkdf2_sha256$870000$seasalt",
+			"pbkdf2_sha256$abc$seasalt$wJSpLMQRQz0Dhj/pFpbyjMj71B2gUYp6HJS5AU+32Ac=",
+			"pbkdf2_sha256$870000$seasalt$!!!",
+		} {
+			if _, err := VerifyDjango(pwd, s); err != ErrInvalidDjango {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidDjango, err)
+			}
+		}
+	})
+}
+
+func TestHashDjango(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	s, err := HashDjango(pwd)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	fields := strings.Split(s, "$")
+	if len(fields) != 4 || fields[0] != "pbkdf2_sha256" || len(fields[2]) != djangoSaltLen {
+		t.Errorf("expected a django pbkdf2_sha256 hash but got '%s'", s)
+	}
+
+	ok, err := VerifyDjango(pwd, s)
+	if !ok || err != nil {
+		t.Errorf("expected hash to be valid, but got '%v'", err)
+	}
+}
@@ -0,0 +1,33 @@
+package hasher
+
+const (
+	// fipsMinIterations is the minimum iteration count allowed in FIPS mode,
+	// as recommended by NIST SP 800-132, section 5.2.
+	fipsMinIterations = 1000
+
+	// fipsMinSaltSize is the minimum salt size, in bytes, allowed in FIPS mode,
+	// as NIST SP 800-132, section 5.1, requires at least 128 bits.
+	fipsMinSaltSize = 16
+
+	// fipsMinKeySize is the minimum stored key size, in bytes, allowed in FIPS mode,
+	// as NIST SP 800-132, section 5.3, requires at least 112 bits.
+	fipsMinKeySize = 14
+)
+
+// fipsAlgorithms are the hash keys of the FIPS-approved algorithms,
+// which may be used as the pbkdf2 PRF, or to pre-hash passwords.
+var fipsAlgorithms = map[int]bool{
+	HashSHA256: true,
+	HashSHA512: true,
+}
+
+// returns a flag which determines whether or not the given parameters are FIPS
+// approved, where a pre-hash of 0 means the password is not pre-hashed, and the
+// salt and key sizes are in bytes.
+func fipsApproved(hashKey, preHash, iterCnt, saltSize, keySize int) bool {
+	return fipsAlgorithms[hashKey] &&
+		(preHash == 0 || fipsAlgorithms[preHash]) &&
+		iterCnt >= fipsMinIterations &&
+		saltSize >= fipsMinSaltSize &&
+		keySize >= fipsMinKeySize
+}
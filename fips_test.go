@@ -0,0 +1,50 @@
+package hasher
+
+import "testing"
+
+func TestWithFIPSMode(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithFIPSMode(true))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, _ := hasher.Hash(pwd)
+	if !hasher.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Non-Compliant Config", func(t *testing.T) {
+		testCases := []struct {
+			Name     string
+			IterCnt  int
+			SaltSize int
+			Opts     []Option
+		}{
+			{Name: "Iterations", IterCnt: 999, SaltSize: DefaultSaltSize},
+			{Name: "Salt Size", IterCnt: DefaultIterationCount, SaltSize: 64},
+			{Name: "Truncation", IterCnt: DefaultIterationCount, SaltSize: DefaultSaltSize, Opts: []Option{WithKeyTruncation(8)}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				opts := append(tc.Opts, WithFIPSMode(true))
+
+				_, err := New(tc.IterCnt, tc.SaltSize, DefaultKeySize, DefaultHashKey, opts...)
+				if err != ErrNotFIPSApproved {
+					t.Errorf("expected '%v' but got '%v'", ErrNotFIPSApproved, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Non-Compliant Hash", func(t *testing.T) {
+		weak, _ := New(500, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+		hash, _ := weak.Hash(pwd)
+
+		if err := hasher.VerifyWithReason(pwd, hash); err != ErrNotFIPSApproved {
+			t.Errorf("expected '%v' but got '%v'", ErrNotFIPSApproved, err)
+		}
+	})
+}
@@ -0,0 +1,638 @@
+package hasher
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// formatMarker is used to indicate the start of a version 1 hash,
+	// which has no explicit version number.
+	formatMarker = 0x01
+
+	// formatMagic is used to indicate the start of a hash which has an
+	// explicit version number, stored in the following byte.
+	formatMagic = 0xAD
+
+	// HeaderVersion is the version of the hash format produced by Hash.
+	HeaderVersion = 2
+)
+
+const (
+	// headerSizeV1 is the number of bytes used by a version 1 header, which
+	// consists of the format marker and three 4-byte values.
+	headerSizeV1 = 13
+
+	// headerSizeV2 is the number of bytes used by a version 2 header, which
+	// consists of the format magic, the version, a flags byte and three
+	// 4-byte values.
+	headerSizeV2 = 15
+)
+
+// flags used in version 2 headers to indicate optional features. Each flag
+// which is set may add an optional value to the header, following the salt
+// length, in the order the flags are defined.
+const (
+	// flagPreHash indicates the password was pre-hashed before being passed to
+	// pbkdf2. The pre-hash's hash key is stored as an optional 4-byte value.
+	flagPreHash byte = 1 << iota
+
+	// flagTimestamp indicates the time the hash was created is stored as
+	// an optional 8-byte value, in unix seconds.
+	flagTimestamp
+
+	// flagKeyLen indicates the length of the sub-key, in bytes, is stored as an
+	// optional 4-byte value. Without it, the sub-key is assumed to be the
+	// remainder of the hash, following the salt.
+	flagKeyLen
+
+	// flagContext indicates the hash was derived with a context, see WithContext.
+	// The context itself is not stored, so it has no optional value.
+	flagContext
+
+	// flagOuterHash indicates the derived sub-key was passed through an outer HMAC,
+	// see WithOuterHash. The outer hash's hash key is stored as an optional 4-byte value.
+	flagOuterHash
+
+	// flagIdentity indicates the hash was derived with an identity, see HashWithIdentity.
+	// The identity itself is not stored, so it has no optional value.
+	flagIdentity
+
+	// knownFlags is a mask of all the flags supported by this version.
+	knownFlags = flagPreHash | flagTimestamp | flagKeyLen | flagContext | flagOuterHash | flagIdentity
+)
+
+// Errors returned when reading a hash.
+var (
+	ErrInvalidFormat      = errors.New("hash is in an invalid format")
+	ErrUnsupportedVersion = errors.New("unsupported hash format version")
+	ErrEmptySalt          = errors.New("hash has an empty salt")
+
+	// ErrKeyLengthMismatch is returned when the stored sub-key is shorter than the
+	// length declared in the hash's header, which indicates the hash was truncated,
+	// such as by a column too narrow to hold it. It wraps ErrInvalidFormat.
+	ErrKeyLengthMismatch = fmt.Errorf("%w: stored key is shorter than the length in its header", ErrInvalidFormat)
+)
+
+// OutputLen returns the number of bytes a hash will occupy when hashed
+// with the given salt and key sizes, in bits.
+func OutputLen(saltBits, keyBits int) int {
+	return OutputLenVersion(HeaderVersion, saltBits, keyBits)
+}
+
+// OutputLenVersion returns the number of bytes a hash, in the given format
+// version, will occupy when hashed with the given salt and key sizes, in bits.
+//
+// Options which record additional values in the header, such as WithPreHash,
+// are not accounted for. The header size of CompactHeaderVersion varies with its
+// values, so the most bytes it can take is used. Will return 0 if the version is
+// not supported.
+func OutputLenVersion(version, saltBits, keyBits int) int {
+	size := headerLen(version)
+	if size == 0 {
+		return 0
+	}
+
+	return size + saltBits/8 + keyBits/8
+}
+
+// HashInfo describes the parameters a hash was produced with.
+type HashInfo struct {
+	// Version is the format version of the hash.
+	Version int
+
+	// Algorithm is the hash key of the algorithm used, such as HashSHA256.
+	Algorithm int
+
+	// Iterations is the iteration count.
+	Iterations int
+
+	// SaltSize and KeySize are the sizes of the salt and sub-key, in bits,
+	// as given to New.
+	SaltSize int
+	KeySize  int
+
+	// PreHash is the hash key of the pre-hash algorithm, or 0 if the
+	// password was not pre-hashed.
+	PreHash int
+
+	// CreatedAt is the time the hash was created, or the zero time if
+	// it was not recorded, see WithTimestamp.
+	CreatedAt time.Time
+
+	// Context determines whether or not the hash was derived with a
+	// context, see WithContext.
+	Context bool
+
+	// OuterHash is the hash key of the outer hash algorithm, or 0 if the
+	// sub-key was not passed through an outer hash, see WithOuterHash.
+	OuterHash int
+
+	// Identity determines whether or not the hash was derived with an
+	// identity, see HashWithIdentity.
+	Identity bool
+}
+
+// Inspect reads the header of the given hash, returning the parameters
+// it was produced with. A non-nil error will be returned if the hash is
+// in an invalid format, the format version is not supported, or the
+// salt is empty, in which case, the error is ErrEmptySalt.
+func Inspect(hash []byte) (HashInfo, error) {
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return HashInfo{}, err
+	}
+
+	info := HashInfo{
+		Version:    hdr.version,
+		Algorithm:  hdr.hashKey,
+		Iterations: hdr.iterCnt,
+		SaltSize:   hdr.saltLen * 8,
+		KeySize:    len(hdr.subKey(hash)) * 8,
+		PreHash:    hdr.preHash,
+		Context:    hdr.flags&flagContext != 0,
+		OuterHash:  hdr.outerHash,
+		Identity:   hdr.flags&flagIdentity != 0,
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		info.CreatedAt = time.Unix(hdr.created, 0)
+	}
+
+	return info, nil
+}
+
+// IsDefaultParams returns true if the hash was produced with the default parameters,
+// DefaultIterationCount, DefaultSaltSize, DefaultKeySize and DefaultHashKey, as used by
+// the package-level Hash function. The default iteration count is low, so accounts with
+// such hashes can be prioritized when sweeping stored hashes for rehashing.
+//
+// Will return false if the hash is in an invalid format, see Inspect.
+func IsDefaultParams(hash []byte) bool {
+	info, err := Inspect(hash)
+	if err != nil {
+		return false
+	}
+
+	return info.Algorithm == DefaultHashKey &&
+		info.Iterations == DefaultIterationCount &&
+		info.SaltSize == DefaultSaltSize &&
+		info.KeySize == DefaultKeySize
+}
+
+// ParamDelta describes a parameter of a hash which differs from a target Config,
+// as returned by Diff. Have is the hash's value and Want the target's, so, for
+// example, the hash's iteration count is too low if Have is less than Want.
+type ParamDelta struct {
+	// Field is the name of the parameter, one of "algorithm", "iterations",
+	// "saltSize" or "keySize". Sizes are in bits, as in Config.
+	Field string
+
+	Have int
+	Want int
+}
+
+// Diff compares the parameters of the hash to the target config, returning a delta for
+// each which differs, in the order algorithm, iterations, salt size, then key size, or
+// none if they all match. This explains why a hash needs rehashing, for example, in an
+// admin UI, where NeedsRehash only reports that it does.
+//
+// The target isn't validated, see ValidateConfig. A non-nil error will be returned if
+// the hash is in an invalid format, see Inspect.
+func Diff(hash []byte, target Config) ([]ParamDelta, error) {
+	info, err := Inspect(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var deltas []ParamDelta
+	for _, d := range []ParamDelta{
+		{Field: "algorithm", Have: info.Algorithm, Want: target.HashKey},
+		{Field: "iterations", Have: info.Iterations, Want: target.IterationCount},
+		{Field: "saltSize", Have: info.SaltSize, Want: target.SaltSize},
+		{Field: "keySize", Have: info.KeySize, Want: target.KeySize},
+	} {
+		if d.Have != d.Want {
+			deltas = append(deltas, d)
+		}
+	}
+
+	return deltas, nil
+}
+
+// Reencode returns the hash rewritten in the target format version, such as to migrate
+// stored hashes to CompactHeaderVersion, offline, without the passwords. Only the header
+// is re-encoded, as the salt and sub-key are the same in every version, so no key is
+// derived, and the password still verifies against the returned hash. A hash already in
+// the target version is returned as a copy, with any bytes following a sub-key of
+// declared length removed.
+//
+// A non-nil error will be returned if the hash is in an invalid format, see Inspect,
+// ErrUnsupportedVersion if the target version isn't supported, or ErrInvalidFormatVersion
+// if the target version can't represent the hash's parameters, such as a version 1 target
+// for a hash with a pre-hash, or any other optional values.
+func Reencode(hash []byte, targetVersion int) ([]byte, error) {
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if headerLen(targetVersion) == 0 {
+		return nil, ErrUnsupportedVersion
+	}
+
+	if targetVersion == 1 && hdr.flags != 0 {
+		// version 1 headers have no flags, to record optional values.
+		return nil, ErrInvalidFormatVersion
+	}
+
+	salt, subKey, err := hdr.components(hash, SaltBeforeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr.version = targetVersion
+
+	out := make([]byte, hdr.len()+len(salt)+len(subKey))
+	n := writeHeader(out, hdr)
+	copy(out[n:], salt)
+	copy(out[n+len(salt):], subKey)
+
+	return out, nil
+}
+
+// fingerprintLen is the number of bytes of the digest used by Fingerprint.
+const fingerprintLen = 8
+
+// Fingerprint returns a short identifier for the hash, which can be used to
+// reference it in logs and support tickets, without exposing the hash itself.
+//
+// The fingerprint is the first 8 bytes of the SHA256 digest of the hash's header and
+// salt, encoded as 16 hex characters. The sub-key is never included, so a fingerprint
+// gives no help to an offline attack on the password. As the salt is random, hashes of
+// the same password still have distinct fingerprints.
+//
+// Will return an empty string if the hash is in an invalid format.
+func Fingerprint(hash []byte) string {
+	sum, err := headerDigest(hash)
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sum[:fingerprintLen])
+}
+
+// Redact returns a representation of the hash which is safe to include in logs and
+// error messages, containing its algorithm and parameters, in the same format as
+// HashString, but with the salt and sub-key replaced by placeholders of their size:
+//
+//	pbkdf2-sha256$i=1000$salt(16B)$key(32B)
+//
+// If the hash is in an invalid format, or its algorithm is not recognised, only its
+// size is returned, such as "invalid(12B)".
+func Redact(hash []byte) string {
+	invalid := fmt.Sprintf("invalid(%dB)", len(hash))
+
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return invalid
+	}
+
+	name, ok := algNames[hdr.hashKey]
+	if !ok {
+		return invalid
+	}
+
+	params, err := encodeParams(hdr)
+	if err != nil {
+		return invalid
+	}
+
+	return fmt.Sprintf("%s%s$%s$salt(%dB)$key(%dB)", phcPrefix, name, params, hdr.saltLen, len(hdr.subKey(hash)))
+}
+
+// ErrInvalidShards is returned by ShardKey when the number of shards is not positive.
+var ErrInvalidShards = errors.New("number of shards must be positive")
+
+// ShardKey returns the shard, in the range [0, shards), the hash should be stored in,
+// for password stores which are sharded horizontally. The same hash always returns the
+// same shard, and as the salt is random, hashes are distributed uniformly between shards.
+//
+// As with Fingerprint, the shard is derived from the SHA256 digest of the hash's header
+// and salt, so the sub-key is never exposed to the storage layer.
+//
+// A non-nil error will be returned if shards is not positive, or the hash is in an
+// invalid format.
+func ShardKey(hash []byte, shards int) (int, error) {
+	if shards < 1 {
+		return 0, ErrInvalidShards
+	}
+
+	sum, err := headerDigest(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	// the bias of reducing a 64-bit value is negligible for any realistic number of shards.
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(shards)), nil
+}
+
+// returns the SHA256 digest of the hash's header and salt.
+func headerDigest(hash []byte) ([sha256.Size]byte, error) {
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(hash[:hdr.size+hdr.saltLen]), nil
+}
+
+// header contains the information stored at the start of a hash.
+type header struct {
+	version int
+	flags   byte
+	hashKey int
+	iterCnt int
+	saltLen int
+	preHash int
+	created int64
+	keyLen  int
+
+	outerHash int
+
+	// size is the number of bytes the header occupied, when scanned.
+	size int
+}
+
+// returns the number of bytes needed to write the header.
+func (hdr header) len() int {
+	if hdr.version == CompactHeaderVersion {
+		return hdr.compactLen()
+	}
+
+	size := headerLen(hdr.version)
+	if hdr.flags&flagPreHash != 0 {
+		size += 4
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		size += 8
+	}
+
+	if hdr.flags&flagKeyLen != 0 {
+		size += 4
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		size += 4
+	}
+
+	return size
+}
+
+// returns the sub-key from a hash with the scanned header. If the header declares
+// the sub-key's length, any trailing bytes are ignored, otherwise, the sub-key is
+// the remainder of the hash.
+func (hdr header) subKey(hash []byte) []byte {
+	offset := hdr.size + hdr.saltLen
+	if hdr.flags&flagKeyLen != 0 {
+		return hash[offset : offset+hdr.keyLen]
+	}
+
+	return hash[offset:]
+}
+
+// SaltPosition is the order of the salt and sub-key following the header of a hash.
+type SaltPosition int
+
+const (
+	// SaltBeforeKey is the default position, where the salt is followed by
+	// the sub-key. Hash always uses this position.
+	SaltBeforeKey SaltPosition = iota
+
+	// SaltAfterKey is the position used by legacy formats which store the
+	// sub-key followed by the salt. Hashes must declare the sub-key's length
+	// in their header, so the boundary between the two is unambiguous.
+	SaltAfterKey
+)
+
+// returns the salt and sub-key from a hash with the scanned header, in the
+// given position. The header must declare the sub-key's length, unless the
+// salt precedes the sub-key.
+func (hdr header) components(hash []byte, pos SaltPosition) (salt, subKey []byte, err error) {
+	if pos == SaltBeforeKey {
+		return hash[hdr.size : hdr.size+hdr.saltLen], hdr.subKey(hash), nil
+	}
+
+	if hdr.flags&flagKeyLen == 0 {
+		return nil, nil, fmt.Errorf("%w: the key length is required to read the salt after the key", ErrCorruptHash)
+	}
+
+	offset := hdr.size + hdr.keyLen
+
+	return hash[offset : offset+hdr.saltLen], hash[hdr.size:offset], nil
+}
+
+// returns the number of bytes used by a header in the given version, without
+// optional values, or 0 if the version is not supported. The size of a compact
+// header varies, so the most bytes it can take is returned.
+func headerLen(version int) int {
+	switch version {
+	case 1:
+		return headerSizeV1
+	case 2:
+		return headerSizeV2
+	case CompactHeaderVersion:
+		return maxCompactHeaderLen
+	default:
+		return 0
+	}
+}
+
+// writes the header to the start of buf, returning the number of bytes written.
+//
+// The version 1 layout is:
+//
+//	[0]     format marker (0x01)
+//	[1:5]   hash key
+//	[5:9]   iteration count
+//	[9:13]  salt length
+//
+// The version 2 layout is:
+//
+//	[0]     format magic (0xAD)
+//	[1]     version
+//	[2]     flags, indicating optional features
+//	[3:7]   hash key
+//	[7:11]  iteration count
+//	[11:15] salt length
+//	[15:19] pre-hash key, if flagPreHash is set
+//	[...+8] creation time in unix seconds, if flagTimestamp is set
+//	[...+4] sub-key length, if flagKeyLen is set
+//	[...+4] outer hash key, if flagOuterHash is set
+//
+// Optional values are only present if their flag is set, so the offset of each depends
+// on the flags which precede it. All values are written big-endian, and are followed by
+// the salt and sub-key. The same layout is described programmatically by BinaryLayout,
+// which must be kept in sync.
+func writeHeader(buf []byte, hdr header) int {
+	offset := 1
+
+	switch hdr.version {
+	case CompactHeaderVersion:
+		return writeCompactHeader(buf, hdr)
+	case 1:
+		buf[0] = formatMarker
+	default:
+		buf[0] = formatMagic
+		buf[1] = byte(hdr.version)
+		buf[2] = hdr.flags
+		offset = 3
+	}
+
+	writeHeaderValue(buf, offset, uint(hdr.hashKey))
+	writeHeaderValue(buf, offset+4, uint(hdr.iterCnt))
+	writeHeaderValue(buf, offset+8, uint(hdr.saltLen))
+	offset += 12
+
+	if hdr.flags&flagPreHash != 0 {
+		writeHeaderValue(buf, offset, uint(hdr.preHash))
+		offset += 4
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		writeHeaderValue(buf, offset, uint(uint64(hdr.created)>>32))
+		writeHeaderValue(buf, offset+4, uint(uint32(hdr.created)))
+		offset += 8
+	}
+
+	if hdr.flags&flagKeyLen != 0 {
+		writeHeaderValue(buf, offset, uint(hdr.keyLen))
+		offset += 4
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		writeHeaderValue(buf, offset, uint(hdr.outerHash))
+		offset += 4
+	}
+
+	return offset
+}
+
+// writes header data using the given offset and value, as a 4-byte big-endian integer.
+func writeHeaderValue(buf []byte, offset int, value uint) {
+	buf[offset+0] = byte(value >> 24)
+	buf[offset+1] = byte(value >> 16)
+	buf[offset+2] = byte(value >> 8)
+	buf[offset+3] = byte(value >> 0)
+}
+
+// reads header data from the given offset, as a 4-byte big-endian integer.
+func readHeaderValue(buf []byte, offset int) int {
+	return int(buf[offset+0])<<24 | int(buf[offset+1])<<16 | int(buf[offset+2])<<8 | int(buf[offset+3])
+}
+
+// scans a hash for the header information, such as version, algorithm, iteration count and salt size,
+// dispatching on the format version. A non-nil error is returned if the header is invalid.
+func scanHeader(buf []byte) (hdr header, err error) {
+	if len(buf) < 1 {
+		return hdr, ErrInvalidFormat
+	}
+
+	offset := 1
+
+	switch buf[0] {
+	case formatMarker:
+		// version 1 hashes have no explicit version.
+		hdr.version = 1
+	case formatMagic:
+		if len(buf) < 2 {
+			return hdr, ErrInvalidFormat
+		}
+
+		hdr.version = int(buf[1])
+		if hdr.version != HeaderVersion && hdr.version != CompactHeaderVersion {
+			return hdr, ErrUnsupportedVersion
+		}
+
+		if len(buf) < compactHeaderPrefix {
+			return hdr, ErrInvalidFormat
+		}
+
+		hdr.flags = buf[2]
+		if hdr.flags&^knownFlags != 0 {
+			return hdr, ErrInvalidFormat
+		}
+
+		offset = 3
+	default:
+		return hdr, ErrInvalidFormat
+	}
+
+	if hdr.version == CompactHeaderVersion {
+		if err := scanCompactHeader(buf, &hdr); err != nil {
+			return hdr, err
+		}
+
+		return hdr, checkComponents(buf, hdr)
+	}
+
+	hdr.size = hdr.len()
+	if len(buf) < hdr.size {
+		return hdr, ErrInvalidFormat
+	}
+
+	hdr.hashKey = readHeaderValue(buf, offset)
+	hdr.iterCnt = readHeaderValue(buf, offset+4)
+	hdr.saltLen = readHeaderValue(buf, offset+8)
+	offset += 12
+
+	if hdr.flags&flagPreHash != 0 {
+		hdr.preHash = readHeaderValue(buf, offset)
+		offset += 4
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		hdr.created = int64(uint64(readHeaderValue(buf, offset))<<32 | uint64(readHeaderValue(buf, offset+4)))
+		offset += 8
+	}
+
+	if hdr.flags&flagKeyLen != 0 {
+		hdr.keyLen = readHeaderValue(buf, offset)
+		offset += 4
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		hdr.outerHash = readHeaderValue(buf, offset)
+	}
+
+	return hdr, checkComponents(buf, hdr)
+}
+
+// returns a non-nil error if the hash with the scanned header is too short to
+// contain the salt and sub-key it declares, or it declares an empty salt. If only
+// the sub-key is too short, ErrKeyLengthMismatch is returned.
+func checkComponents(buf []byte, hdr header) error {
+	if hdr.saltLen < 0 || len(buf) < hdr.size+hdr.saltLen {
+		return ErrInvalidFormat
+	}
+
+	if hdr.saltLen == 0 {
+		// a hash is never produced without a salt, so it's corrupt, or crafted.
+		return ErrEmptySalt
+	}
+
+	if hdr.keyLen < 0 {
+		return ErrInvalidFormat
+	}
+
+	if len(buf) < hdr.size+hdr.saltLen+hdr.keyLen {
+		return ErrKeyLengthMismatch
+	}
+
+	return nil
+}
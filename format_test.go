@@ -0,0 +1,378 @@
+package hasher
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	hasher, _ := New(5000, 256, 512, HashSHA512, WithPreHash(HashSHA256))
+	hash, _ := hasher.Hash([]byte("MyTestPassword"))
+
+	info, err := Inspect(hash)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	expected := HashInfo{
+		Version:    HeaderVersion,
+		Algorithm:  HashSHA512,
+		Iterations: 5000,
+		SaltSize:   256,
+		KeySize:    512,
+		PreHash:    HashSHA256,
+	}
+
+	if info != expected {
+		t.Errorf("expected %+v but got %+v", expected, info)
+	}
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		_, err := Inspect([]byte{0x23})
+		if err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+
+	t.Run("Unsupported Version", func(t *testing.T) {
+		_, err := Inspect([]byte{formatMagic, CompactHeaderVersion + 1})
+		if err != ErrUnsupportedVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedVersion, err)
+		}
+	})
+
+	t.Run("Empty Salt", func(t *testing.T) {
+		// a crafted header, declaring a salt length of zero.
+		hash := make([]byte, headerSizeV2+32)
+		writeHeader(hash, header{version: HeaderVersion, hashKey: DefaultHashKey, iterCnt: DefaultIterationCount})
+
+		_, err := Inspect(hash)
+		if err != ErrEmptySalt {
+			t.Errorf("expected '%v' but got '%v'", ErrEmptySalt, err)
+		}
+
+		if err := defaultHasher.VerifyWithReason([]byte("MyTestPassword"), hash); err != ErrEmptySalt {
+			t.Errorf("expected '%v' but got '%v'", ErrEmptySalt, err)
+		}
+	})
+}
+
+func TestIsDefaultParams(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	stronger, _ := New(DefaultIterationCount*2, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	sha512, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512)
+
+	testCases := []struct {
+		Name     string
+		Hash     []byte
+		Expected bool
+	}{
+		{"Default", mustHash(t, pwd), true},
+		{"Iterations", mustHashWith(t, stronger, pwd), false},
+		{"Algorithm", mustHashWith(t, sha512, pwd), false},
+		{"Malformed", []byte{formatMagic}, false},
+		{"Empty", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if ok := IsDefaultParams(tc.Hash); ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	target := Config{
+		IterationCount: DefaultIterationCount,
+		SaltSize:       DefaultSaltSize,
+		KeySize:        DefaultKeySize,
+		HashKey:        DefaultHashKey,
+	}
+
+	weaker, _ := New(500, DefaultSaltSize, 128, HashSHA512)
+
+	testCases := []struct {
+		Name     string
+		Hash     []byte
+		Expected []ParamDelta
+	}{
+		{"Matching", mustHash(t, pwd), nil},
+		{"Differing", mustHashWith(t, weaker, pwd), []ParamDelta{
+			{Field: "algorithm", Have: HashSHA512, Want: DefaultHashKey},
+			{Field: "iterations", Have: 500, Want: DefaultIterationCount},
+			{Field: "keySize", Have: 128, Want: DefaultKeySize},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			deltas, err := Diff(tc.Hash, target)
+			if err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+
+			if !reflect.DeepEqual(deltas, tc.Expected) {
+				t.Errorf("expected '%+v' but got '%+v'", tc.Expected, deltas)
+			}
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		if _, err := Diff([]byte{formatMagic}, target); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestReencode(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	versions := []int{1, HeaderVersion, CompactHeaderVersion}
+
+	for _, from := range versions {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithOutputFormatVersion(from))
+		hash := mustHashWith(t, h, pwd)
+		expected, _ := Inspect(hash)
+
+		for _, to := range versions {
+			t.Run(fmt.Sprintf("%d To %d", from, to), func(t *testing.T) {
+				reencoded, err := Reencode(hash, to)
+				if err != nil {
+					t.Fatalf("didn't expect to get an error: %v", err)
+				}
+
+				info, _ := Inspect(reencoded)
+				if info.Version != to {
+					t.Errorf("expected version '%d' but got '%d'", to, info.Version)
+				}
+
+				info.Version = expected.Version
+				if info != expected {
+					t.Errorf("expected '%+v' but got '%+v'", expected, info)
+				}
+
+				if !Verify(pwd, reencoded) {
+					t.Errorf("expected reencoded hash to be valid")
+				}
+
+				// round-trip back to the original version.
+				back, _ := Reencode(reencoded, from)
+				if !bytes.Equal(back, hash) {
+					t.Errorf("expected '%x' but got '%x'", hash, back)
+				}
+			})
+		}
+	}
+
+	t.Run("Options", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithPreHash(HashSHA512), WithTimestamp(true), WithKeyLengthInHeader(true), WithOuterHash(HashSHA512))
+		hash := mustHashWith(t, h, pwd)
+
+		compact, err := Reencode(hash, CompactHeaderVersion)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if len(compact) >= len(hash) || !h.Verify(pwd, compact) {
+			t.Errorf("expected a smaller, valid hash")
+		}
+
+		if _, err := Reencode(hash, 1); err != ErrInvalidFormatVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormatVersion, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := Reencode(mustHash(t, pwd), CompactHeaderVersion+1); err != ErrUnsupportedVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedVersion, err)
+		}
+
+		if _, err := Reencode([]byte{formatMagic}, HeaderVersion); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	hash := mustHash(t, []byte("MyTestPassword"))
+
+	fp := Fingerprint(hash)
+	if len(fp) != fingerprintLen*2 {
+		t.Errorf("expected '%d' characters but got '%d'", fingerprintLen*2, len(fp))
+	}
+
+	if Fingerprint(mustHash(t, []byte("MyTestPassword"))) == fp {
+		t.Errorf("expected hashes with different salts to have different fingerprints")
+	}
+
+	t.Run("Ignores Sub-Key", func(t *testing.T) {
+		modified := make([]byte, len(hash))
+		copy(modified, hash)
+		modified[len(modified)-1] ^= 0xFF
+
+		if Fingerprint(modified) != fp {
+			t.Errorf("expected the fingerprint not to depend on the sub-key")
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if fp := Fingerprint([]byte{0x23}); fp != "" {
+			t.Errorf("expected an empty fingerprint but got '%s'", fp)
+		}
+	})
+}
+
+func TestRedact(t *testing.T) {
+	h, _ := New(1000, 128, 256, HashSHA256, WithPreHash(HashSHA512))
+	hash, _ := h.Hash([]byte("MyTestPassword"))
+
+	expected := "pbkdf2-sha256$i=1000,ph=sha512$salt(16B)$key(32B)"
+	if s := Redact(hash); s != expected {
+		t.Errorf("expected '%s' but got '%s'", expected, s)
+	}
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if s := Redact([]byte{0x23, 0x01}); s != "invalid(2B)" {
+			t.Errorf("expected 'invalid(2B)' but got '%s'", s)
+		}
+	})
+}
+
+func TestShardKey(t *testing.T) {
+	hash := mustHash(t, []byte("MyTestPassword"))
+
+	shard, err := ShardKey(hash, 16)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if again, _ := ShardKey(hash, 16); again != shard {
+		t.Errorf("expected the same shard for the same hash, but got '%d' and '%d'", shard, again)
+	}
+
+	t.Run("Ignores Sub-Key", func(t *testing.T) {
+		modified := append([]byte{}, hash...)
+		modified[len(modified)-1] ^= 0xFF
+
+		if s, _ := ShardKey(modified, 16); s != shard {
+			t.Errorf("expected the shard not to depend on the sub-key")
+		}
+	})
+
+	t.Run("Uniform", func(t *testing.T) {
+		const shards, n = 10, 10000
+
+		// only the header and salt are used, so hashes are built directly with random salts.
+		hdr := header{version: HeaderVersion, hashKey: DefaultHashKey, iterCnt: DefaultIterationCount, saltLen: DefaultSaltSize / 8}
+		buf := make([]byte, hdr.len()+hdr.saltLen+DefaultKeySize/8)
+		writeHeader(buf, hdr)
+
+		r := rand.New(rand.NewSource(1))
+		counts := make([]int, shards)
+		for i := 0; i < n; i++ {
+			r.Read(buf[hdr.len() : hdr.len()+hdr.saltLen])
+
+			s, err := ShardKey(buf, shards)
+			if err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+
+			counts[s]++
+		}
+
+		for s, c := range counts {
+			// each shard should have n/shards hashes, give or take 10%.
+			if c < n/shards*9/10 || c > n/shards*11/10 {
+				t.Errorf("expected shard %d to have ~%d hashes, but got %d", s, n/shards, c)
+			}
+		}
+	})
+
+	t.Run("Invalid Shards", func(t *testing.T) {
+		for _, shards := range []int{0, -1} {
+			if _, err := ShardKey(hash, shards); err != ErrInvalidShards {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidShards, err)
+			}
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if _, err := ShardKey([]byte{0x23}, 16); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+}
+
+// returns a sample of uint32 values, including the boundaries of each byte, and
+// random values with every possible most significant byte, so each shift is covered.
+func headerValueSamples() []uint32 {
+	samples := []uint32{0, 1, 0xFF, 0x100, 0xFFFF, 0x10000, 0xFFFFFF, 0x1000000,
+		math.MaxInt32, math.MaxInt32 + 1, math.MaxUint32 - 1, math.MaxUint32}
+
+	r := rand.New(rand.NewSource(1))
+	for msb := uint32(0); msb < 256; msb++ {
+		samples = append(samples, msb<<24|r.Uint32()>>8)
+	}
+
+	return samples
+}
+
+func TestHeaderValueRoundTrip(t *testing.T) {
+	buf := make([]byte, headerSizeV2+16)
+
+	for offset := 0; offset+4 <= len(buf); offset++ {
+		for _, value := range headerValueSamples() {
+			writeHeaderValue(buf, offset, uint(value))
+
+			// values above math.MaxInt32 are negative on 32-bit platforms, so compare as uint32.
+			if v := uint32(readHeaderValue(buf, offset)); v != value {
+				t.Fatalf("offset %d: expected '%d' but got '%d'", offset, value, v)
+			}
+		}
+	}
+}
+
+func TestScanHeaderRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, value := range headerValueSamples() {
+		expected := header{
+			version: HeaderVersion,
+			flags:   flagPreHash | flagTimestamp | flagKeyLen,
+			hashKey: int(value),
+			iterCnt: int(value),
+			saltLen: 1,
+			preHash: int(value),
+			created: r.Int63() - r.Int63(),
+			keyLen:  1,
+		}
+
+		buf := make([]byte, expected.len()+expected.saltLen+expected.keyLen)
+		writeHeader(buf, expected)
+
+		hdr, err := scanHeader(buf)
+		if err != nil {
+			t.Fatalf("%d: didn't expect to get an error: %v", value, err)
+		}
+
+		if uint32(hdr.hashKey) != value || uint32(hdr.iterCnt) != value || uint32(hdr.preHash) != value {
+			t.Errorf("%d: expected the values to round-trip but got '%+v'", value, hdr)
+		}
+
+		if hdr.created != expected.created {
+			t.Errorf("expected a creation time of '%d' but got '%d'", expected.created, hdr.created)
+		}
+
+		if hdr.saltLen != expected.saltLen || hdr.keyLen != expected.keyLen {
+			t.Errorf("expected the salt and key lengths to round-trip but got '%+v'", hdr)
+		}
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/reecerussell/adaptive-password-hasher
 
-go 1.14
+go 1.20
 
 require (
 	github.com/golang/mock v1.4.4
@@ -1,22 +1,64 @@
 package hasher
 
 import (
-	"crypto/rand"
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"crypto/sha512"
 	"crypto/subtle"
 	"errors"
 	"fmt"
 	"hash"
-
-	"golang.org/x/crypto/pbkdf2"
+	"io"
+	"log"
+	"math/bits"
+	"sort"
+	"time"
 )
 
 // Common errors.
 var (
-	ErrInvalidIterationCount = errors.New("iteration count must be at least 1")
-	ErrInvalidSaltSize       = errors.New("salt size must be positive and divisible by 8")
-	ErrInvalidKeySize        = errors.New("key size must be positive and divisinle by 8")
+	ErrInvalidIterationCount    = errors.New("iteration count must be at least 1")
+	ErrInvalidSaltSize          = errors.New("salt size must be positive and divisible by 8")
+	ErrInvalidKeySize           = errors.New("key size must be positive and divisinle by 8")
+	ErrInvalidKeyTruncation     = errors.New("key truncation must be positive and no greater than the key size")
+	ErrInvalidKeyLength         = errors.New("key length must be positive")
+	ErrAmbiguousKeyLength       = errors.New("key length does not imply a single algorithm")
+	ErrKeyTooLarge              = errors.New("key size exceeds the pbkdf2 limit of (2^32 - 1) * hLen")
+	ErrExpandTooLarge           = errors.New("key length exceeds the HKDF limit of 255 * hLen")
+	ErrUnsupportedHashKey       = errors.New("unsupported hash key")
+	ErrInvalidVerifyCache       = errors.New("verify cache size and ttl must be positive")
+	ErrInvalidMaxAge            = errors.New("max age must not be negative")
+	ErrInvalidConcurrency       = errors.New("concurrency limit must be positive")
+	ErrInvalidAllowedAlgorithms = errors.New("allowed algorithms must be supported, and include the hasher's")
+	ErrInvalidContext           = errors.New("context must not be empty")
+	ErrNotFIPSApproved          = errors.New("parameters are not FIPS approved")
+	ErrInvalidSaltPosition      = errors.New("salt position must be SaltBeforeKey or SaltAfterKey")
+	ErrNullByte                 = errors.New("password contains a null byte")
+	ErrInvalidMaxPasswordLength = errors.New("max password length must be positive")
+	ErrInvalidIterationRatio    = errors.New("min iteration ratio must be between 0 and 1")
+	ErrInvalidTimeout           = errors.New("timeout must not be negative")
+	ErrInvalidFormatVersion     = errors.New("output format version is not supported, or doesn't support the hasher's options")
+	ErrInvalidAlgorithmSunset   = errors.New("sunset algorithms must be supported, and exclude the hasher's")
+	ErrInvalidOuterHash         = errors.New("outer hash must be supported, with a digest at least as long as the key size")
+	ErrInvalidAdaptiveCost      = errors.New("adaptive cost target latency and interval must be positive")
+	ErrInvalidMinHashDuration   = errors.New("min hash duration must not be negative")
+	ErrInvalidParameterWindow   = errors.New("parameter window bounds must not be negative, or inverted, and must contain the hasher's parameters")
+	ErrInvalidIdentity          = errors.New("identity must not be empty")
+	ErrInvalidSaltEntropy       = errors.New("min salt entropy must be positive, and no more than 6, or 1 less than log2 of the salt size, in bytes")
+)
+
+// Errors returned by VerifyWithReason.
+var (
+	ErrPasswordMismatch = errors.New("password does not match the hash")
+	ErrHashTooWeak      = errors.New("hash salt size, key size or iteration count is less than the hasher's")
+	ErrHashTooStrong    = errors.New("hash salt size, key size or iteration count is greater than the hasher's maximum")
+	ErrCorruptHash      = errors.New("hash is corrupt")
+
+	ErrAlgorithmNotAllowed = errors.New("hash algorithm is not allowed")
+	ErrAlgorithmSunset     = errors.New("hash algorithm has been retired")
+	ErrContextMismatch     = errors.New("hash context does not match the hasher's")
+	ErrIdentityMismatch    = errors.New("hash was derived with an identity, but none was given, or vice versa")
 )
 
 const (
@@ -28,6 +70,13 @@ const (
 	// to use the SHA512 hashing algorithm.
 	HashSHA512 = 2
 
+	// HashBcryptPBKDF is the hash key used to derive keys with OpenBSD's
+	// bcrypt_pbkdf, rather than pbkdf2, where the iteration count is the number
+	// of rounds. It's primarily intended for reading keys derived in the same way
+	// as OpenSSH private keys, so is only supported by DeriveKey and
+	// VerifyHeaderless, and can't be used with New.
+	HashBcryptPBKDF = 3
+
 	// DefaultIterationCount is the default number of times a
 	// password will be hashed.
 	DefaultIterationCount = 1000
@@ -46,8 +95,37 @@ const (
 // the pbkdf2 key derivation algorithm. Using an adaptive format, passwords
 // can be hashed using different hash algorithms and key sizes.
 type Hasher interface {
-	Hash(pwd []byte) []byte
+	Hash(pwd []byte) ([]byte, error)
+	HashAppend(dst, pwd []byte) ([]byte, error)
+	HashWithProgress(pwd []byte, progress func(done, total int)) ([]byte, error)
+	HashWithIdentity(pwd, identity []byte) ([]byte, error)
+	HashContext(ctx context.Context, pwd []byte) ([]byte, error)
+	HashString(pwd []byte) (string, error)
+	HashPasswordReader(r io.Reader) ([]byte, error)
 	Verify(pwd, hash []byte) bool
+	VerifyWithReason(pwd, hash []byte) error
+	VerifyExpectingAlgorithm(pwd, hash []byte, expectedAlg int) bool
+	VerifyWithAlgorithm(pwd, hash []byte) (ok bool, algName string)
+	Verifier(hash []byte) (func(pwd []byte) bool, error)
+	VerifyWithPeppers(pwd, hash []byte, peppers ...[]byte) bool
+	VerifyWithIdentity(pwd, hash, identity []byte) bool
+	VerifyNotCompromised(pwd, hash []byte, blocklist BloomFilter) (verified, compromised bool)
+	VerifyTimingSafe(pwd, hash []byte, budget time.Duration) bool
+	VerifyContext(ctx context.Context, pwd, hash []byte) (bool, error)
+	VerifyBatch(jobs []VerifyJob) []bool
+	VerifyBatchContext(ctx context.Context, jobs []VerifyJob) ([]bool, error)
+	VerifyString(pwd []byte, s string) bool
+	HashSplit(pwd []byte) (headerAndKey, salt []byte, err error)
+	VerifySplit(pwd, headerAndKey, salt []byte) (bool, error)
+	VerifyPasswordReader(r io.Reader, hash []byte) (bool, error)
+	VerifyRecord(pwd []byte, r HashRecord) (bool, error)
+	Profile(pwd, hash []byte) (ProfileReport, error)
+	Algorithm() int
+	Params() Config
+	Compatible(other Hasher) bool
+	NeedsRehash(hash []byte) bool
+	DeriveKeys(pwd, salt []byte, sizes ...int) ([][]byte, error)
+	VerifyAndDeriveKey(pwd, hash []byte, extraKeyLen int) (ok bool, sessionKey []byte)
 }
 
 func init() {
@@ -63,7 +141,7 @@ func init() {
 var defaultHasher Hasher
 
 // Hash hases the given password using the default hasher.
-func Hash(pwd []byte) []byte {
+func Hash(pwd []byte) ([]byte, error) {
 	return defaultHasher.Hash(pwd)
 }
 
@@ -77,6 +155,47 @@ type hasher struct {
 	saltSize int
 	keySize  int
 	hashKey  int
+	truncLen int
+	truncate bool
+	tracker  *SaltTracker
+	preHash  int
+	outer    int
+	allowed  map[int]bool
+	sunsets  map[int]time.Time
+	context  []byte
+	fips     bool
+	noNulls  bool
+	saltPos  SaltPosition
+	minRatio float64
+	winMin   *Config
+	winMax   *Config
+
+	maxPwdLen     int
+	version       int
+	ignoreVersion bool
+
+	saltSource SaltSource
+	minEntropy float64
+
+	timestamp bool
+	keyLenHdr bool
+	maxAge    time.Duration
+	now       func() time.Time
+
+	cacheSize int
+	cacheTTL  time.Duration
+	cache     *verifyCache
+
+	concurrency int
+	limited     bool
+	slots       chan struct{}
+
+	timeout time.Duration
+	minTime time.Duration
+
+	adaptTarget time.Duration
+	adaptEvery  int
+	adaptive    *adaptiveCost
 }
 
 // New returns a new Hasher, configured with the given values.
@@ -84,132 +203,948 @@ type hasher struct {
 // Both saltSize and keySize are recognised as number of bits. So,
 // the given values must be divisible by 8, for the number of bytes.
 //
+// Optional behaviour can be configured by passing one or more Options.
+//
 // A non-nil error will be returned if any of the values are invalid.
-func New(iterCtn, saltSize, keySize, hashKey int) (Hasher, error) {
-	if iterCtn < 1 {
-		return nil, ErrInvalidIterationCount
-	}
-
-	if saltSize%8 != 0 || saltSize/8 < 1 {
-		return nil, ErrInvalidSaltSize
+func New(iterCtn, saltSize, keySize, hashKey int, opts ...Option) (Hasher, error) {
+	c := Config{
+		IterationCount: iterCtn,
+		SaltSize:       saltSize,
+		KeySize:        keySize,
+		HashKey:        hashKey,
 	}
 
-	if keySize%8 != 0 || keySize/8 < 1 {
-		return nil, ErrInvalidKeySize
+	// validating the hash key up-front means alg can't panic when hashing.
+	if errs := c.validate(); len(errs) > 0 {
+		return nil, errs[0]
 	}
 
-	return &hasher{
+	h := &hasher{
 		iterCnt:  iterCtn,
 		saltSize: saltSize / 8,
 		keySize:  keySize / 8,
 		hashKey:  hashKey,
-	}, nil
+
+		maxPwdLen: DefaultMaxPasswordLength,
+		version:   HeaderVersion,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// options may change the algorithm, such as WithRecommendedAlgorithm.
+	if err := checkKeyLen(keySize, alg(h.hashKey)); err != nil {
+		return nil, err
+	}
+
+	if h.minEntropy != 0 && !(h.minEntropy > 0 && h.minEntropy <= maxMinSaltEntropy(h.saltSize)) {
+		// NaN fails both comparisons.
+		return nil, ErrInvalidSaltEntropy
+	}
+
+	if h.saltSource == nil {
+		h.saltSource = randSource{}
+	}
+
+	if h.now == nil {
+		h.now = time.Now
+	}
+
+	if !h.supportsVersion() {
+		return nil, ErrInvalidFormatVersion
+	}
+
+	if !(h.minRatio >= 0 && h.minRatio <= 1) {
+		// NaN fails both comparisons.
+		return nil, ErrInvalidIterationRatio
+	}
+
+	if h.winMin != nil {
+		// the hasher must be able to verify its own hashes.
+		if !validWindow(*h.winMin, *h.winMax) || h.checkWindow(h.iterCnt, h.saltSize, h.storedKeySize()) != nil {
+			return nil, ErrInvalidParameterWindow
+		}
+	}
+
+	if h.maxPwdLen < 1 {
+		return nil, ErrInvalidMaxPasswordLength
+	}
+
+	if h.maxAge < 0 {
+		return nil, ErrInvalidMaxAge
+	}
+
+	if h.timeout < 0 {
+		return nil, ErrInvalidTimeout
+	}
+
+	if h.minTime < 0 {
+		return nil, ErrInvalidMinHashDuration
+	}
+
+	if h.truncate && (h.truncLen < 1 || h.truncLen > h.keySize) {
+		return nil, ErrInvalidKeyTruncation
+	}
+
+	if _, ok := lookupAlg(h.preHash); h.preHash != 0 && !ok {
+		return nil, ErrUnsupportedHashKey
+	}
+
+	if outerFunc, ok := lookupAlg(h.outer); h.outer != 0 && (!ok || outerFunc().Size() < h.storedKeySize()) {
+		return nil, ErrInvalidOuterHash
+	}
+
+	if h.fips && !fipsApproved(h.hashKey, h.preHash, h.iterCnt, h.saltSize, h.storedKeySize()) {
+		return nil, ErrNotFIPSApproved
+	}
+
+	if h.saltPos != SaltBeforeKey && h.saltPos != SaltAfterKey {
+		return nil, ErrInvalidSaltPosition
+	}
+
+	if h.context != nil && len(h.context) == 0 {
+		return nil, ErrInvalidContext
+	}
+
+	if h.allowed != nil && !h.allowed[h.hashKey] {
+		return nil, ErrInvalidAllowedAlgorithms
+	}
+
+	for key := range h.allowed {
+		if _, ok := lookupAlg(key); !ok {
+			return nil, ErrInvalidAllowedAlgorithms
+		}
+	}
+
+	for key := range h.sunsets {
+		if _, ok := lookupAlg(key); !ok || key == h.hashKey {
+			return nil, ErrInvalidAlgorithmSunset
+		}
+	}
+
+	if h.limited {
+		if h.concurrency < 1 {
+			return nil, ErrInvalidConcurrency
+		}
+
+		h.slots = make(chan struct{}, h.concurrency)
+	}
+
+	if h.adaptTarget != 0 || h.adaptEvery != 0 {
+		if h.adaptTarget <= 0 || h.adaptEvery < 1 {
+			return nil, ErrInvalidAdaptiveCost
+		}
+
+		h.adaptive = newAdaptiveCost(h.adaptTarget, h.adaptEvery, h.iterCnt)
+	}
+
+	if h.cacheSize != 0 || h.cacheTTL != 0 {
+		if h.cacheSize < 1 || h.cacheTTL <= 0 {
+			return nil, ErrInvalidVerifyCache
+		}
+
+		cache, err := newVerifyCache(h.cacheSize, h.cacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		h.cache = cache
+	}
+
+	return h, nil
 }
 
-// formatMarker is used to indicate the start of the hash.
-const formatMarker = 0x01
+// Compatible returns true if hashes produced by the hasher verify under the other, and
+// vice versa, so it can be used to check services sharing a password store haven't
+// drifted apart. Both must have the same Params, and, if the other was also returned by
+// New, the same context and key truncation, which also determine whether hashes verify.
+//
+// Version 1 hashes verify under either hasher, so the output format version is ignored.
+func (h *hasher) Compatible(other Hasher) bool {
+	if other == nil || h.Params() != other.Params() {
+		return false
+	}
+
+	o, ok := other.(*hasher)
+	if !ok {
+		return true
+	}
+
+	return bytes.Equal(h.context, o.context) && h.storedKeySize() == o.storedKeySize()
+}
+
+// Algorithm returns the hash key of the algorithm the hasher is configured to use.
+func (h *hasher) Algorithm() int {
+	return h.hashKey
+}
+
+// Params returns the parameters the hasher is configured with, as given to New, or
+// changed by an Option, such as WithRecommendedAlgorithm. The sizes are in bits.
+func (h *hasher) Params() Config {
+	return Config{
+		IterationCount: h.iterCnt,
+		SaltSize:       h.saltSize * 8,
+		KeySize:        h.keySize * 8,
+		HashKey:        h.hashKey,
+	}
+}
+
+// String returns a description of the hasher's parameters, for logging, in
+// the format "pbkdf2-<algorithm>(iter=<iterations>, salt=<bits>b, key=<bits>b)".
+func (h *hasher) String() string {
+	return fmt.Sprintf("%s%s(iter=%d, salt=%db, key=%db)", phcPrefix, algNames[h.hashKey], h.iterCnt, h.saltSize*8, h.keySize*8)
+}
+
+// NeedsRehash returns true if the hash was not produced with the hasher's
+// current parameters, and should be replaced by hashing the password again, the
+// next time it's verified. This is the case if either:
+//   - the hash is in a different format version to the hasher's output, see
+//     WithOutputFormatVersion, or an invalid format,
+//   - the algorithm or pre-hash algorithm differs from the hasher's,
+//   - the hash was derived with a context, and the hasher has none, or vice versa,
+//   - the iteration count, salt size or key size is less than the hasher's,
+//   - or a max age is set with WithMaxAge, and the hash is older, or its
+//     creation time was not recorded.
+func (h *hasher) NeedsRehash(hash []byte) bool {
+	info, err := Inspect(hash)
+	if err != nil {
+		return true
+	}
+
+	if h.maxAge > 0 && (info.CreatedAt.IsZero() || h.now().Sub(info.CreatedAt) > h.maxAge) {
+		return true
+	}
+
+	return info.Version != h.version ||
+		info.Algorithm != h.hashKey ||
+		info.PreHash != h.preHash ||
+		info.OuterHash != h.outer ||
+		info.Context != (h.context != nil) ||
+		info.Iterations < h.iterCnt ||
+		info.SaltSize < h.saltSize*8 ||
+		info.KeySize < h.storedKeySize()*8
+}
+
+// returns true if the hasher's output format version is supported, and can record
+// every option the hasher uses. Version 1 headers have no flags, so can't record
+// a pre-hash, creation time, key length, context or outer hash.
+func (h *hasher) supportsVersion() bool {
+	switch h.version {
+	case 1:
+		return h.preHash == 0 && !h.timestamp && !h.keyLenHdr && h.context == nil && h.outer == 0
+	case HeaderVersion, CompactHeaderVersion:
+		return true
+	default:
+		return false
+	}
+}
+
+// returns the number of sub-key bytes which are stored in a hash.
+func (h *hasher) storedKeySize() int {
+	if h.truncate {
+		return h.truncLen
+	}
+
+	return h.keySize
+}
 
 // Hash hashes the given password data using the pbkdf2, key derivation
 // algorithm. The output will contain, hash information alongside the salt
 // and sub-key data.
-func (h *hasher) Hash(pwd []byte) []byte {
-	salt := make([]byte, h.saltSize)
-	rand.Read(salt)
-	subKey := pbkdf2.Key(pwd, salt, h.iterCnt, h.keySize, alg(h.hashKey))
+//
+// Passwords are hashed as arbitrary bytes, so null bytes are preserved, and hashed
+// along with the rest of the password, unless WithRejectNullBytes is used.
+//
+// A non-nil error will be returned if a salt could not be generated, or
+// ErrNullByte if the password is rejected by WithRejectNullBytes.
+func (h *hasher) Hash(pwd []byte) ([]byte, error) {
+	return h.hash(context.Background(), nil, pwd, nil, nil)
+}
+
+// HashAppend hashes the given password, in the same way as Hash, appending the hash
+// to dst and returning the extended slice, in the style of append. If dst has enough
+// spare capacity, the hash is written into it, rather than a new slice, so callers
+// which pool their own buffers can hash without allocating the output. A fresh salt is
+// still generated for every call.
+//
+// On error, dst is returned unchanged, along with the error, as returned by Hash.
+func (h *hasher) HashAppend(dst, pwd []byte) ([]byte, error) {
+	out, err := h.hash(context.Background(), dst, pwd, nil, nil)
+	if err != nil {
+		return dst, err
+	}
 
-	out := make([]byte, 13+h.saltSize+h.keySize)
-	out[0] = formatMarker // format marker
+	return out, nil
+}
+
+// HashWithProgress hashes the given password data, in the same way as Hash,
+// invoking the progress callback periodically as iterations complete. This is
+// useful for reporting progress to a user, when using a high iteration count.
+//
+// The callback is given the number of iterations done so far and the total
+// number of iterations, which are reported once all iterations are complete.
+//
+// A non-nil error will be returned if a salt could not be generated.
+func (h *hasher) HashWithProgress(pwd []byte, progress func(done, total int)) ([]byte, error) {
+	return h.hash(context.Background(), nil, pwd, nil, progress)
+}
 
-	// write header hasher info
-	writeHeaderValue(out, 1, uint(h.hashKey))
-	writeHeaderValue(out, 5, uint(h.iterCnt))
-	writeHeaderValue(out, 9, uint(len(salt)))
+// HashContext hashes the given password data, in the same way as Hash. If the hasher
+// was configured using WithConcurrencyLimit, and no slot is free, the context's error
+// is returned if it's done before a slot becomes free.
+//
+// The context is only used while waiting, so hashing is not interrupted once started.
+func (h *hasher) HashContext(ctx context.Context, pwd []byte) ([]byte, error) {
+	return h.hash(ctx, nil, pwd, nil, nil)
+}
 
-	// copy data to output
-	copy(out[13:], salt)
-	copy(out[13+len(salt):], subKey)
+// hashes the given password, with the identity, if non-nil, appending the hash to dst,
+// and reporting progress to the callback, if non-nil.
+func (h *hasher) hash(ctx context.Context, dst, pwd, identity []byte, progress func(done, total int)) ([]byte, error) {
+	if h.noNulls && bytes.IndexByte(pwd, 0) >= 0 {
+		return nil, ErrNullByte
+	}
 
-	return out
+	if h.minTime > 0 {
+		// deferred first, so it runs after the slot is released.
+		start := time.Now()
+		defer func() {
+			sleepContext(ctx, h.minTime-time.Since(start))
+		}()
+	}
+
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	saltBuf := getKeyBuffer()
+	defer putKeyBuffer(saltBuf)
+
+	salt, err := h.generateSalt(saltBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.tracker != nil {
+		if err := h.tracker.Track(salt); err != nil {
+			log.Printf("hasher: %v, the random number generator may be broken", err)
+		}
+	}
+
+	hdr := header{
+		version: h.version,
+		hashKey: h.hashKey,
+		iterCnt: h.iterations(),
+		saltLen: len(salt),
+	}
+
+	if h.preHash != 0 {
+		hdr.flags |= flagPreHash
+		hdr.preHash = h.preHash
+		pwd = preHash(pwd, h.preHash)
+	}
+
+	if h.timestamp {
+		hdr.flags |= flagTimestamp
+		hdr.created = h.now().Unix()
+	}
+
+	if h.keyLenHdr {
+		hdr.flags |= flagKeyLen
+		hdr.keyLen = h.storedKeySize()
+	}
+
+	if h.context != nil {
+		hdr.flags |= flagContext
+	}
+
+	if identity != nil {
+		hdr.flags |= flagIdentity
+	}
+
+	if h.outer != 0 {
+		hdr.flags |= flagOuterHash
+		hdr.outerHash = h.outer
+	}
+
+	keyBuf := getKeyBuffer()
+	defer putKeyBuffer(keyBuf)
+
+	// the context is only used while waiting for a slot, so hashing is
+	// only interrupted by the hasher's timeout, if it has one.
+	deriveCtx, cancel := h.withTimeout(context.Background())
+	defer cancel()
+
+	start := time.Now()
+
+	subKey, err := deriveKeyContext(deriveCtx, keyBuf, pwd, h.contextSalt(salt, identity), hdr.iterCnt, h.keySize, alg(h.hashKey), progress)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.adaptive != nil {
+		h.adaptive.observe(hdr.iterCnt, time.Since(start))
+	}
+
+	subKey = subKey[:h.storedKeySize()]
+	if h.outer != 0 {
+		subKey = outerHash(subKey, h.outer)
+	}
+
+	out, tail := grow(dst, hdr.len()+len(salt)+len(subKey))
+	n := writeHeader(tail, hdr)
+
+	// copy data to output, as the salt and sub-key buffers are reused.
+	copy(tail[n:], salt)
+	copy(tail[n+len(salt):], subKey)
+
+	return out, nil
 }
 
-// writes header data using the given offset and value.
-func writeHeaderValue(buf []byte, offset int, value uint) {
-	buf[offset+0] = byte(value >> 24)
-	buf[offset+1] = byte(value >> 16)
-	buf[offset+2] = byte(value >> 8)
-	buf[offset+3] = byte(value >> 0)
+// extends b by n bytes, reusing its spare capacity if there's enough, returning
+// the extended slice, and the n bytes at its end, which are for the caller to fill.
+func grow(b []byte, n int) (out, tail []byte) {
+	if total := len(b) + n; total <= cap(b) {
+		out = b[:total]
+	} else {
+		out = make([]byte, total)
+		copy(out, b)
+	}
+
+	return out, out[len(b):]
 }
 
 // Verify hashed the given password and compares it to the given hash data,
 // returning a flag which determines whether or not the password matches the hash.
+// Hashes in any supported format version can be verified, and the password is
+// only pre-hashed if the hash's header says it was, regardless of the hasher's options.
 //
 // Will return false if either:
-//     - the hash salt size is less than the hasher's salt size,
-//     - the hash key size is less than the hasher's key size,
-//     - or if the hash is in an invalid format.
-func (h *hasher) Verify(pwd, hash []byte) (ok bool) {
+//   - the hash algorithm is not allowed, see WithAllowedAlgorithms,
+//   - the hash algorithm's sunset has passed, see WithAlgorithmSunset,
+//   - FIPS mode is enabled, and the hash's parameters are not approved, see WithFIPSMode,
+//   - the hash was derived with a context, and the hasher has none, or vice versa,
+//   - the hash salt size is less than the hasher's salt size,
+//   - the hash key size is less than the hasher's key size,
+//   - the hash parameters fall outside the window, see WithParameterWindow,
+//   - or if the hash is in an invalid format.
+//
+// If the hasher was configured using WithVerifyCache, a cached result may be returned.
+func (h *hasher) Verify(pwd, hash []byte) bool {
+	ok, _ := h.VerifyContext(context.Background(), pwd, hash)
+	return ok
+}
+
+// VerifyContext verifies the password against the hash, in the same way as Verify.
+// If the hasher was configured using WithConcurrencyLimit, and no slot is free, the
+// context's error is returned if it's done before a slot becomes free. Cached results
+// are returned without waiting for a slot.
+//
+// The context is also checked periodically during the derivation, which is stopped if
+// it's done, returning an *InterruptedError with the number of iterations completed.
+func (h *hasher) VerifyContext(ctx context.Context, pwd, hash []byte) (bool, error) {
+	if h.cache == nil {
+		return h.verifyContext(ctx, pwd, hash)
+	}
+
+	d := h.cache.digest(pwd, hash)
+	if ok, found := h.cache.get(d); found {
+		return ok, nil
+	}
+
+	ok, err := h.verifyContext(ctx, pwd, hash)
+	if err != nil {
+		return false, err
+	}
+
+	h.cache.put(d, ok)
+
+	return ok, nil
+}
+
+// verifies the password against the hash, once a slot is acquired, returning
+// a non-nil error if the context is done first.
+func (h *hasher) verifyContext(ctx context.Context, pwd, hash []byte) (bool, error) {
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	err = h.verify(ctx, pwd, hash)
+
+	// the error is never wrapped, and asserting its type, rather than
+	// using errors.As, means verifying doesn't allocate.
+	if _, ok := err.(*InterruptedError); ok {
+		return false, err
+	}
+
+	return err == nil, nil
+}
+
+// VerifyWithReason verifies the password against the hash, in the same way as Verify,
+// but returns the reason verification failed, rather than a flag. Will return nil if
+// the password matches, ErrPasswordMismatch if it doesn't, or another error if the
+// hash couldn't be verified, such as ErrInvalidFormat or ErrHashTooWeak.
+//
+// A hash whose stored sub-key is shorter than the length declared in its header, see
+// WithKeyLengthInHeader, returns ErrKeyLengthMismatch, rather than ErrPasswordMismatch,
+// so truncated records can be told apart from wrong passwords. Without a declared
+// length, the sub-key is the remainder of the hash, so truncation can't be detected.
+//
+// If reading the hash panics, the panic is recovered and returned as an error wrapping
+// ErrCorruptHash, which includes the panic's detail, and the hash, redacted using Redact,
+// to help diagnose malformed hashes.
+//
+// Results are never read from, or written to, the verify cache.
+func (h *hasher) VerifyWithReason(pwd, hash []byte) error {
+	release, _ := h.acquire(context.Background())
+	defer release()
+
+	return h.verify(context.Background(), pwd, hash)
+}
+
+// VerifyExpectingAlgorithm verifies the password against the hash, in the same way as
+// Verify, but returns false if the hash's algorithm is not expectedAlg.
+//
+// The algorithm is read from the hash's header, so an attacker with write access to
+// stored hashes could replace a hash with one using a weaker algorithm, which they can
+// compute more cheaply, without it being rejected by Verify. Binding verification
+// to an algorithm stored out-of-band, such as a per-user policy, closes this downgrade.
+func (h *hasher) VerifyExpectingAlgorithm(pwd, hash []byte, expectedAlg int) bool {
+	hdr, err := h.readHeader(hash)
+	if err != nil || hdr.hashKey != expectedAlg {
+		return false
+	}
+
+	return h.Verify(pwd, hash)
+}
+
+// VerifyWithAlgorithm verifies the password against the hash, in the same way as Verify,
+// also returning the name of the hash's algorithm, such as "sha256", for labelling
+// metrics by algorithm, without a separate call to Inspect. The name is returned whether
+// or not the password matches, as long as the hash's header is valid, and its algorithm
+// is supported, otherwise it's empty.
+func (h *hasher) VerifyWithAlgorithm(pwd, hash []byte) (ok bool, algName string) {
+	hdr, err := h.readHeader(hash)
+	if err != nil {
+		return false, ""
+	}
+
+	return h.Verify(pwd, hash), algNames[hdr.hashKey]
+}
+
+// Verifier parses the hash once, returning a function which verifies a password against
+// it, in the same way as Verify, for verifying many passwords against the same stored
+// hash, such as in a retry loop, without parsing it each time. The parsed salt and
+// sub-key are copied, so the hash can be reused once Verifier returns.
+//
+// The hash's algorithm is checked when the verifier is created, including its sunset,
+// see WithAlgorithmSunset, so a verifier shouldn't outlive the request it's created for.
+// Results aren't cached, see WithVerifyCache.
+//
+// A non-nil error will be returned if the hash is in an invalid format, or its algorithm
+// can't be used by the hasher, as returned by VerifyWithReason.
+func (h *hasher) Verifier(hash []byte) (func(pwd []byte) bool, error) {
+	hdr, err := h.readHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.checkAlgorithm(hdr.hashKey); err != nil {
+		return nil, err
+	}
+
+	salt, expected, err := hdr.components(hash, h.saltPos)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, expected = append([]byte{}, salt...), append([]byte{}, expected...)
+
+	return func(pwd []byte) bool {
+		release, _ := h.acquire(context.Background())
+		defer release()
+
+		return h.verifyComponents(context.Background(), pwd, nil, hdr, salt, expected, nil, nil) == nil
+	}, nil
+}
+
+// VerifyTimingSafe verifies the password against the hash, in the same way as Verify,
+// then sleeps until budget has elapsed since the call started. Every call takes the same
+// time, from the caller's perspective, which masks differences in cost between hashes
+// with different parameters, and failures which return early, such as invalid hashes.
+//
+// The budget must exceed the worst-case cost of verification, including any time spent
+// waiting for WithConcurrencyLimit, otherwise calls which overrun it return as soon as
+// they're done, and timing is no longer flattened.
+func (h *hasher) VerifyTimingSafe(pwd, hash []byte, budget time.Duration) bool {
+	return verifyTimingSafe(h, pwd, hash, budget)
+}
+
+// verifies the password against the hash using the Hasher, padding the time taken to the budget.
+func verifyTimingSafe(h Hasher, pwd, hash []byte, budget time.Duration) bool {
+	start := time.Now()
+	ok := h.Verify(pwd, hash)
+
+	time.Sleep(budget - time.Since(start))
+
+	return ok
+}
+
+// returns the iteration count to use for new hashes, which is the hasher's, unless
+// it's configured with WithAdaptiveCost.
+func (h *hasher) iterations() int {
+	if h.adaptive == nil {
+		return h.iterCnt
+	}
+
+	return h.adaptive.iterations()
+}
+
+// sleeps for d, or until the context is done, whichever is first.
+func sleepContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// returns a context which is done once the hasher's timeout elapses, or the given
+// context is done, along with a function to release its resources. If the hasher
+// has no timeout, the context is returned as-is, so no resources are allocated.
+func (h *hasher) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.timeout == 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, h.timeout)
+}
+
+// acquires a slot from the hasher's concurrency limit, waiting until one is free,
+// returning a func to release it. If the context is done first, its error is returned.
+func (h *hasher) acquire(ctx context.Context) (release func(), err error) {
+	if h.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		return func() { <-h.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// verifies the password against the hash, without using the cache, returning
+// the reason verification failed, or nil if the password matches. The derivation
+// is stopped if the context is done, returning an *InterruptedError.
+func (h *hasher) verify(ctx context.Context, pwd, hash []byte) error {
+	return h.verifyWith(ctx, pwd, nil, hash, nil, nil)
+}
+
+// scans the hash's header, in the same way as scanHeader, unless the hash is in a
+// format version newer than any supported, and WithIgnoreUnknownFormatVersion is
+// enabled, in which case it's scanned as if it were in HeaderVersion's layout.
+func (h *hasher) readHeader(hash []byte) (header, error) {
+	hdr, err := scanHeader(hash)
+	if err != ErrUnsupportedVersion || !h.ignoreVersion || int(hash[1]) < HeaderVersion {
+		return hdr, err
+	}
+
+	// the version is replaced in a copy, so the hash itself is left as it is.
+	current := append([]byte{}, hash...)
+	current[1] = HeaderVersion
+
+	return scanHeader(current)
+}
+
+// verifies the password, with the identity, if non-nil, against the hash, in the same
+// way as verify, calling matched, if non-nil, with the hash's header, the (pre-hashed)
+// password, the salt given to pbkdf2 and the derived sub-key, if the password matches.
+// The sub-key is wiped once matched returns, and any error it returns is returned. The
+// time spent in each stage is recorded by prof, if non-nil.
+func (h *hasher) verifyWith(ctx context.Context, pwd, identity, hash []byte, prof *profiler, matched func(hdr header, pwd, salt, subKey []byte) error) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			// this should never occur, unless the given hash was not
 			// originally hashed using the Hash() function, i.e. invalid format
 			// from another third-party hashing function.
-			ok = false
+			// the hash is redacted, so the error is safe to log.
+			err = fmt.Errorf("%w: %v: %s", ErrCorruptHash, r, Redact(hash))
 		}
 	}()
 
-	if hash[0] != formatMarker {
-		return false
+	hdr, err := h.readHeader(hash)
+	if err != nil {
+		return err
 	}
 
-	hashFunc, iterCnt, saltLen := scanHeader(hash)
-	if saltLen < h.saltSize {
-		// saltLen must be >= to the hasher's salt size.
-		return false
+	if err := h.checkAlgorithm(hdr.hashKey); err != nil {
+		return err
 	}
 
-	salt := make([]byte, saltLen)
-	copy(salt[:], hash[13:13+saltLen])
+	prof.lap(stageHeader)
 
-	subKeyLen := len(hash) - 13 - saltLen
-	if subKeyLen < h.keySize {
-		// subKeyLen must be >= to the hasher's key size.
-		return false
+	salt, expected, err := hdr.components(hash, h.saltPos)
+	if err != nil {
+		return err
+	}
+
+	prof.lap(stageSalt)
+
+	return h.verifyComponents(ctx, pwd, identity, hdr, salt, expected, prof, matched)
+}
+
+// returns a non-nil error if the hasher can't verify hashes using the hash key, as it's
+// unsupported, not allowed, see WithAllowedAlgorithms, or retired, see WithAlgorithmSunset.
+func (h *hasher) checkAlgorithm(hashKey int) error {
+	if _, supported := lookupAlg(hashKey); !supported {
+		return ErrUnsupportedHashKey
 	}
 
-	expected := make([]byte, subKeyLen)
-	copy(expected[:], hash[13+saltLen:13+saltLen+subKeyLen])
-	actual := pbkdf2.Key(pwd, salt, iterCnt, subKeyLen, hashFunc)
+	if h.allowed != nil && !h.allowed[hashKey] {
+		return ErrAlgorithmNotAllowed
+	}
+
+	if sunset, ok := h.sunsets[hashKey]; ok && h.now().After(sunset) {
+		return ErrAlgorithmSunset
+	}
 
-	return subtle.ConstantTimeCompare(actual, expected) == 1
+	return nil
 }
 
-// scans a hash for the header information, such as version, algorithm, iteration count and salt size.
-func scanHeader(buf []byte) (hashAlg func() hash.Hash, iterCnt, saltSize int) {
-	for i := 1; i < 13; i += 4 {
-		v := int(buf[i+0])<<24 | int(buf[i+1])<<16 | int(buf[i+2])<<8 | int(buf[i+3])
+// verifies the password against a hash's salt and expected sub-key, with the given header,
+// in the same way as verifyWith, once the header's algorithm has been checked.
+func (h *hasher) verifyComponents(ctx context.Context, pwd, identity []byte, hdr header, salt, expected []byte, prof *profiler, matched func(hdr header, pwd, salt, subKey []byte) error) (err error) {
+	hashFunc := alg(hdr.hashKey)
 
-		switch i {
-		case 1:
-			hashAlg = alg(v)
-		case 5:
-			iterCnt = v
-		case 9:
-			saltSize = v
+	if h.fips && !fipsApproved(hdr.hashKey, hdr.preHash, hdr.iterCnt, hdr.saltLen, len(expected)) {
+		return ErrNotFIPSApproved
+	}
+
+	// the salt must be >= to the hasher's salt size, but rejecting the hash is deferred
+	// until after the derivation, using a dummy salt, so the time taken doesn't reveal
+	// the hasher's salt size to anyone probing with crafted hashes.
+	weakSalt := len(salt) < h.saltSize
+	if weakSalt {
+		salt = make([]byte, h.saltSize)
+	}
+
+	subKeyLen := len(expected)
+	if subKeyLen < h.storedKeySize() {
+		// subKeyLen must be >= to the hasher's (stored) key size.
+		return ErrHashTooWeak
+	}
+
+	if float64(hdr.iterCnt) < h.minRatio*float64(h.iterCnt) {
+		// the iteration count must be >= to the ratio of the hasher's.
+		return ErrHashTooWeak
+	}
+
+	if err := h.checkWindow(hdr.iterCnt, hdr.saltLen, subKeyLen); err != nil {
+		return err
+	}
+
+	if (hdr.flags&flagContext != 0) != (h.context != nil) {
+		// a context is required if, and only if, the hash was derived with one.
+		return ErrContextMismatch
+	}
+
+	if (hdr.flags&flagIdentity != 0) != (identity != nil) {
+		// likewise, an identity is required if, and only if, the hash was derived with one.
+		return ErrIdentityMismatch
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		outerFunc, ok := lookupAlg(hdr.outerHash)
+		if !ok {
+			return ErrUnsupportedHashKey
 		}
+
+		if outerFunc().Size() < subKeyLen {
+			// the outer hash's output can't be longer than its digest.
+			return ErrInvalidFormat
+		}
+	}
+
+	prof.lap(stageChecks)
+
+	if hdr.flags&flagPreHash != 0 {
+		if _, ok := lookupAlg(hdr.preHash); !ok {
+			return ErrUnsupportedHashKey
+		}
+
+		pwd = preHash(pwd, hdr.preHash)
+	}
+
+	buf := getKeyBuffer()
+	defer putKeyBuffer(buf)
+
+	ctx, cancel := h.withTimeout(ctx)
+	defer cancel()
+
+	var actual []byte
+	if hdr.hashKey == HashSHA256 && subKeyLen == sha256.Size && h.context == nil && identity == nil {
+		actual, err = deriveDefaultKey(ctx, buf, pwd, salt, hdr.iterCnt)
+	} else {
+		actual, err = deriveKeyContext(ctx, buf, pwd, h.contextSalt(salt, identity), hdr.iterCnt, subKeyLen, hashFunc, nil)
+	}
+
+	if err == nil && hdr.flags&flagOuterHash != 0 {
+		actual = outerHash(actual, hdr.outerHash)
+		defer wipe(actual)
+	}
+
+	prof.lap(stageDerivation)
+
+	if err != nil {
+		return err
+	}
+
+	if weakSalt {
+		return ErrHashTooWeak
+	}
+
+	if subtle.ConstantTimeCompare(actual, expected) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	if matched != nil {
+		return matched(hdr, pwd, h.contextSalt(salt, identity), actual)
 	}
 
-	return
+	return nil
+}
+
+// returns the salt given to pbkdf2, which is the salt followed by the hasher's context,
+// if it has one, then the SHA-256 digest of the identity, if non-nil. As the salt's
+// length is stored in the header, and the digest's length is fixed, the boundaries
+// between the three are unambiguous.
+func (h *hasher) contextSalt(salt, identity []byte) []byte {
+	if h.context == nil && identity == nil {
+		return salt
+	}
+
+	out := make([]byte, 0, len(salt)+len(h.context)+sha256.Size)
+	out = append(append(out, salt...), h.context...)
+
+	if identity != nil {
+		digest := sha256.Sum256(identity)
+		out = append(out, digest[:]...)
+	}
+
+	return out
+}
+
+// hashes the password with the hash function for the given key, so it can
+// be used as the input to pbkdf2.
+func preHash(pwd []byte, key int) []byte {
+	h := alg(key)()
+	h.Write(pwd)
+
+	return h.Sum(nil)
 }
 
 // returns a hash function for the given key. Will panic id
 // the key is not a recognised hash key.
 func alg(key int) func() hash.Hash {
+	f, ok := lookupAlg(key)
+	if !ok {
+		panic(fmt.Errorf("hash: unsupported hash key: %d", key))
+	}
+
+	return f
+}
+
+// returns a hash function for the given key, and a flag which determines
+// whether or not the key is a recognised hash key.
+func lookupAlg(key int) (func() hash.Hash, bool) {
 	switch key {
 	case HashSHA256:
-		return sha256.New
+		return sha256.New, true
 	case HashSHA512:
-		return sha512.New
+		return sha512.New, true
 	default:
-		panic(fmt.Errorf("hash: unsupported hash key: %d", key))
+		return nil, false
 	}
 }
+
+// RecommendAlgorithm returns the hash key of the algorithm recommended for the
+// current platform. This is HashSHA512 on 64-bit platforms, as SHA512 operates on
+// 64-bit words, so is faster per byte than SHA256 there, letting the same time budget
+// afford more work. On 32-bit platforms, where SHA512 is comparatively slow, the
+// recommendation is HashSHA256.
+//
+// This is only a heuristic, see WithRecommendedAlgorithm.
+func RecommendAlgorithm() int {
+	if bits.UintSize == 64 {
+		return HashSHA512
+	}
+
+	return HashSHA256
+}
+
+// algNames maps the supported hash keys to the names used in textual formats.
+var algNames = map[int]string{
+	HashSHA256: "sha256",
+	HashSHA512: "sha512",
+}
+
+// returns the hash key for the given algorithm name, and a flag which
+// determines whether or not the name is recognised.
+func lookupAlgName(name string) (int, bool) {
+	for key, n := range algNames {
+		if n == name {
+			return key, true
+		}
+	}
+
+	return 0, false
+}
+
+// AlgorithmInfo describes an algorithm supported by New, see SupportedAlgorithms.
+type AlgorithmInfo struct {
+	// Key is the hash key passed to New, such as HashSHA256.
+	Key int
+
+	// Name is the name used in textual formats, such as "sha256".
+	Name string
+
+	// DigestSize is the size of the algorithm's digest, in bytes.
+	DigestSize int
+}
+
+// SupportedAlgorithms returns information about each algorithm supported by New,
+// ordered by hash key, so tooling can enumerate them without hard-coding constants.
+func SupportedAlgorithms() []AlgorithmInfo {
+	infos := make([]AlgorithmInfo, 0, len(algNames))
+	for key, name := range algNames {
+		hashFunc, ok := lookupAlg(key)
+		if !ok {
+			continue
+		}
+
+		infos = append(infos, AlgorithmInfo{
+			Key:        key,
+			Name:       name,
+			DigestSize: hashFunc().Size(),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+
+	return infos
+}
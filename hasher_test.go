@@ -1,6 +1,37 @@
 package hasher
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// hashes the password using the default hasher, failing the test on error.
+func mustHash(t *testing.T, pwd []byte) []byte {
+	t.Helper()
+
+	hash, err := Hash(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	return hash
+}
+
+// hashes the password using the given hasher, failing the test on error.
+func mustHashWith(t *testing.T, h Hasher, pwd []byte) []byte {
+	t.Helper()
+
+	hash, err := h.Hash(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	return hash
+}
 
 func TestNew(t *testing.T) {
 	hasher, err := New(1000, 128, 256, HashSHA256)
@@ -24,50 +55,242 @@ func TestNew(t *testing.T) {
 	t.Run("Invalid Salt Size", func(t *testing.T) {
 		// negative salt size
 		_, err := New(1000, -1, 256, HashSHA256)
-		if err != ErrInvalidSaltSize {
+		if !errors.Is(err, ErrInvalidSaltSize) {
 			t.Errorf("expected '%v' bot got '%v'", ErrInvalidSaltSize, err)
 		}
 
 		// not a multiple of 8
 		_, err = New(1000, 14, 256, HashSHA256)
-		if err != ErrInvalidSaltSize {
+		if !errors.Is(err, ErrInvalidSaltSize) {
 			t.Errorf("expected '%v' bot got '%v'", ErrInvalidSaltSize, err)
 		}
 	})
 
+	t.Run("Unsupported Hash Key", func(t *testing.T) {
+		_, err := New(1000, 128, 256, 237)
+		if err != ErrUnsupportedHashKey {
+			t.Errorf("expected '%v' bot got '%v'", ErrUnsupportedHashKey, err)
+		}
+	})
+
 	t.Run("Invalid Key Size", func(t *testing.T) {
 		// negative key size
 		_, err := New(1000, 128, -1, HashSHA256)
-		if err != ErrInvalidKeySize {
+		if !errors.Is(err, ErrInvalidKeySize) {
 			t.Errorf("expected '%v' bot got '%v'", ErrInvalidKeySize, err)
 		}
 
 		// not a multiple of 8
 		_, err = New(1000, 128, 14, HashSHA256)
-		if err != ErrInvalidKeySize {
+		if !errors.Is(err, ErrInvalidKeySize) {
 			t.Errorf("expected '%v' bot got '%v'", ErrInvalidKeySize, err)
 		}
 	})
 }
 
+func TestAlgorithm(t *testing.T) {
+	for _, key := range []int{HashSHA256, HashSHA512} {
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, key)
+		if a := hasher.Algorithm(); a != key {
+			t.Errorf("expected algorithm %d, but got %d", key, a)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	expected := Config{IterationCount: 1000, SaltSize: 128, KeySize: 256, HashKey: HashSHA512}
+	h, _ := New(expected.IterationCount, expected.SaltSize, expected.KeySize, expected.HashKey)
+
+	if p := h.Params(); p != expected {
+		t.Errorf("expected '%v' but got '%v'", expected, p)
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	newHasher := func(iterCnt, hashKey int, opts ...Option) Hasher {
+		h, err := New(iterCnt, DefaultSaltSize, DefaultKeySize, hashKey, opts...)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		return h
+	}
+
+	h := newHasher(DefaultIterationCount, DefaultHashKey)
+
+	testCases := []struct {
+		Name     string
+		Other    Hasher
+		Expected bool
+	}{
+		{"Same", newHasher(DefaultIterationCount, DefaultHashKey), true},
+		{"Version 1", newHasher(DefaultIterationCount, DefaultHashKey, WithOutputFormatVersion(1)), true},
+		{"Iterations", newHasher(DefaultIterationCount*2, DefaultHashKey), false},
+		{"Algorithm", newHasher(DefaultIterationCount, HashSHA512), false},
+		{"Context", newHasher(DefaultIterationCount, DefaultHashKey, WithContext([]byte("login"))), false},
+		{"Truncated", newHasher(DefaultIterationCount, DefaultHashKey, WithKeyTruncation(16)), false},
+		{"Noop", NoopHasher{}, false},
+		{"Nil", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if ok := h.Compatible(tc.Other); ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+
+			if tc.Other == nil {
+				return
+			}
+
+			if ok := tc.Other.Compatible(h); ok != tc.Expected {
+				t.Errorf("expected the reverse to be '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	h, _ := New(1000, 128, 256, HashSHA256)
+
+	expected := "pbkdf2-sha256(iter=1000, salt=128b, key=256b)"
+	if s := fmt.Sprint(h); s != expected {
+		t.Errorf("expected '%s' but got '%s'", expected, s)
+	}
+}
+
+func TestSupportedAlgorithms(t *testing.T) {
+	expected := []AlgorithmInfo{
+		{Key: HashSHA256, Name: "sha256", DigestSize: 32},
+		{Key: HashSHA512, Name: "sha512", DigestSize: 64},
+	}
+
+	infos := SupportedAlgorithms()
+	if len(infos) != len(expected) {
+		t.Fatalf("expected %d algorithms, but got %d", len(expected), len(infos))
+	}
+
+	for i, info := range infos {
+		if info != expected[i] {
+			t.Errorf("expected '%+v' but got '%+v'", expected[i], info)
+		}
+
+		if _, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, info.Key); err != nil {
+			t.Errorf("expected %s to be supported by New, but got '%v'", info.Name, err)
+		}
+	}
+}
+
+func TestRecommendAlgorithm(t *testing.T) {
+	key := RecommendAlgorithm()
+	if _, ok := lookupAlg(key); !ok {
+		t.Errorf("expected a supported hash key but got %d", key)
+	}
+
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithRecommendedAlgorithm())
+	if a := hasher.Algorithm(); a != key {
+		t.Errorf("expected algorithm %d, but got %d", key, a)
+	}
+}
+
 func TestHash(t *testing.T) {
 	pwd := "MyTestPassword"
-	hash := Hash([]byte(pwd))
+	hash, err := Hash([]byte(pwd))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
 
 	t.Run("Format", func(t *testing.T) {
-		if hash[0] != formatMarker {
-			t.Errorf("expected '%v' at the start of the hash but got '%b'", formatMarker, hash[0])
+		if hash[0] != formatMagic {
+			t.Errorf("expected '%v' at the start of the hash but got '%b'", formatMagic, hash[0])
+		}
+
+		if hash[1] != HeaderVersion {
+			t.Errorf("expected version %d but got %d", HeaderVersion, hash[1])
 		}
 	})
 
 	t.Run("Scan", func(t *testing.T) {
-		_, iterCnt, saltSize := scanHeader(hash)
-		if iterCnt != DefaultIterationCount {
-			t.Errorf("expected an iteration count of %d, but got %d", DefaultIterationCount, iterCnt)
+		hdr, err := scanHeader(hash)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			return
 		}
 
-		if saltSize != DefaultSaltSize/8 {
-			t.Errorf("expected a salt size of %d, but got %d", DefaultSaltSize/8, saltSize)
+		if hdr.hashKey != DefaultHashKey {
+			t.Errorf("expected a hash key of %d, but got %d", DefaultHashKey, hdr.hashKey)
+		}
+
+		if hdr.iterCnt != DefaultIterationCount {
+			t.Errorf("expected an iteration count of %d, but got %d", DefaultIterationCount, hdr.iterCnt)
+		}
+
+		if hdr.saltLen != DefaultSaltSize/8 {
+			t.Errorf("expected a salt size of %d, but got %d", DefaultSaltSize/8, hdr.saltLen)
+		}
+	})
+}
+
+func TestHashAppend(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	prefix := []byte("prefix")
+
+	t.Run("Reuses Capacity", func(t *testing.T) {
+		dst := make([]byte, 0, 256)
+		dst = append(dst, prefix...)
+
+		out, err := h.HashAppend(dst, pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if &out[0] != &dst[0] {
+			t.Errorf("expected the hash to be written into dst's capacity")
+		}
+
+		if !bytes.Equal(out[:len(prefix)], prefix) {
+			t.Errorf("expected '%s' to be kept but got '%s'", prefix, out[:len(prefix)])
+		}
+
+		if !h.Verify(pwd, out[len(prefix):]) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Grows", func(t *testing.T) {
+		out, err := h.HashAppend(prefix[:len(prefix):len(prefix)], pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if !bytes.Equal(out[:len(prefix)], prefix) || !h.Verify(pwd, out[len(prefix):]) {
+			t.Errorf("expected the hash to be appended to '%s'", prefix)
+		}
+	})
+
+	t.Run("Fresh Salt", func(t *testing.T) {
+		buf := make([]byte, 0, 256)
+		first, _ := h.HashAppend(buf, pwd)
+		first = append([]byte{}, first...)
+
+		second, _ := h.HashAppend(buf, pwd)
+		if bytes.Equal(first, second) {
+			t.Errorf("expected each hash to have a fresh salt")
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithRejectNullBytes(true))
+
+		out, err := h.HashAppend(prefix, []byte("pwd\x00"))
+		if err != ErrNullByte {
+			t.Errorf("expected '%v' but got '%v'", ErrNullByte, err)
+		}
+
+		if !bytes.Equal(out, prefix) {
+			t.Errorf("expected '%s' but got '%s'", prefix, out)
 		}
 	})
 }
@@ -107,7 +330,7 @@ func TestAlg(t *testing.T) {
 
 func TestVerify(t *testing.T) {
 	pwd := []byte("MyTestPassword")
-	hash := Hash(pwd)
+	hash, _ := Hash(pwd)
 
 	ok := Verify(pwd, hash)
 	if !ok {
@@ -131,7 +354,7 @@ func TestVerify(t *testing.T) {
 
 	t.Run("Invalid Salt Size", func(t *testing.T) {
 		hasher, _ := New(DefaultIterationCount, 32, DefaultKeySize, DefaultHashKey)
-		hash := hasher.Hash(pwd)
+		hash, _ := hasher.Hash(pwd)
 		ok := Verify(pwd, hash)
 		if ok {
 			t.Errorf("expected hash to be invalid")
@@ -140,7 +363,7 @@ func TestVerify(t *testing.T) {
 
 	t.Run("Invalid Key Size", func(t *testing.T) {
 		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, 128, DefaultHashKey)
-		hash := hasher.Hash(pwd)
+		hash, _ := hasher.Hash(pwd)
 		ok := Verify(pwd, hash)
 		if ok {
 			t.Errorf("expected hash to be invalid")
@@ -153,4 +376,444 @@ func TestVerify(t *testing.T) {
 			t.Errorf("expected hash to be invalid")
 		}
 	})
+
+	t.Run("Version 1", func(t *testing.T) {
+		// build a version 1 hash, as produced by previous versions.
+		salt := make([]byte, DefaultSaltSize/8)
+		subKey, _ := DeriveKey(pwd, salt, DefaultIterationCount, DefaultKeySize/8, DefaultHashKey)
+
+		hash := make([]byte, headerSizeV1+len(salt)+len(subKey))
+		writeHeader(hash, header{
+			version: 1,
+			hashKey: DefaultHashKey,
+			iterCnt: DefaultIterationCount,
+			saltLen: len(salt),
+		})
+		copy(hash[headerSizeV1+len(salt):], subKey)
+
+		if hash[0] != formatMarker {
+			t.Errorf("expected '%v' at the start of the hash but got '%b'", formatMarker, hash[0])
+		}
+
+		ok := Verify(pwd, hash)
+		if !ok {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Unsupported Version", func(t *testing.T) {
+		hash, _ := Hash(pwd)
+		hash[1] = CompactHeaderVersion + 1
+
+		ok := Verify(pwd, hash)
+		if ok {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Unknown Flags", func(t *testing.T) {
+		hash, _ := Hash(pwd)
+		hash[2] = 0x80
+
+		ok := Verify(pwd, hash)
+		if ok {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+}
+
+func TestVerifyWithReason(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash, _ := hasher.Hash(pwd)
+
+	if err := hasher.VerifyWithReason(pwd, hash); err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	weak, _ := New(DefaultIterationCount, 32, DefaultKeySize, DefaultHashKey)
+	weakHash, _ := weak.Hash(pwd)
+
+	unsupported := make([]byte, len(hash))
+	copy(unsupported, hash)
+	writeHeaderValue(unsupported, 3, 237)
+
+	testCases := []struct {
+		Name     string
+		Pwd      []byte
+		Hash     []byte
+		Expected error
+	}{
+		{Name: "Wrong Password", Pwd: []byte("WrongPassword"), Hash: hash, Expected: ErrPasswordMismatch},
+		{Name: "Invalid Format", Pwd: pwd, Hash: []byte{0x23}, Expected: ErrInvalidFormat},
+		{Name: "Weak Hash", Pwd: pwd, Hash: weakHash, Expected: ErrHashTooWeak},
+		{Name: "Unsupported Hash Key", Pwd: pwd, Hash: unsupported, Expected: ErrUnsupportedHashKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := hasher.VerifyWithReason(tc.Pwd, tc.Hash)
+			if err != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+			}
+		})
+	}
+}
+
+func TestVerifyWeakSaltDerives(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(5000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+	weak, _ := New(5000, 32, DefaultKeySize, DefaultHashKey)
+	weakHash, _ := weak.Hash(pwd)
+
+	// a cancelled context interrupts the derivation, so an interrupted
+	// error shows the hash wasn't rejected before deriving a key.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var interrupted *InterruptedError
+	if _, err := h.VerifyContext(ctx, pwd, weakHash); !errors.As(err, &interrupted) {
+		t.Errorf("expected an interrupted derivation, but got '%v'", err)
+	}
+}
+
+func TestVerifyExpectingAlgorithm(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512)
+	hash, _ := hasher.Hash(pwd)
+
+	if !hasher.VerifyExpectingAlgorithm(pwd, hash, HashSHA512) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Downgraded", func(t *testing.T) {
+		downgraded := mustHash(t, pwd)
+		if hasher.VerifyExpectingAlgorithm(pwd, downgraded, HashSHA512) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if hasher.VerifyExpectingAlgorithm(pwd, []byte{0x23}, HashSHA512) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+}
+
+func TestVerifyWithAlgorithm(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	sha512Hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512)
+	sha512Hash, _ := sha512Hasher.Hash(pwd)
+
+	unsupported := mustHash(t, pwd)
+	writeHeaderValue(unsupported, 3, 237)
+
+	testCases := []struct {
+		Name    string
+		Pwd     []byte
+		Hash    []byte
+		OK      bool
+		AlgName string
+	}{
+		{"SHA256", pwd, mustHash(t, pwd), true, "sha256"},
+		{"SHA512", pwd, sha512Hash, true, "sha512"},
+		{"Mismatch", []byte("NotMyPassword"), sha512Hash, false, "sha512"},
+		{"Unsupported", pwd, unsupported, false, ""},
+		{"Invalid Format", pwd, []byte{0x23}, false, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, algName := defaultHasher.VerifyWithAlgorithm(tc.Pwd, tc.Hash)
+			if ok != tc.OK || algName != tc.AlgName {
+				t.Errorf("expected (%v, '%s') but got (%v, '%s')", tc.OK, tc.AlgName, ok, algName)
+			}
+		})
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash := mustHashWith(t, h, pwd)
+	sha512Only, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithAllowedAlgorithms(HashSHA512))
+
+	buf := append([]byte{}, hash...)
+	verify, err := h.Verifier(buf)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	// the verifier must not depend on the hash's buffer once it's created.
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	for i := 0; i < 3; i++ {
+		if !verify(pwd) {
+			t.Errorf("expected password to be valid")
+		}
+
+		if verify([]byte("wrong")) {
+			t.Errorf("expected password to be invalid")
+		}
+	}
+
+	testCases := []struct {
+		Name     string
+		Hasher   Hasher
+		Hash     []byte
+		Expected error
+	}{
+		{"Malformed", h, []byte{formatMagic}, ErrInvalidFormat},
+		{"Not Allowed", sha512Only, hash, ErrAlgorithmNotAllowed},
+		{"Noop Hash", NoopHasher{}, hash, ErrInvalidFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if _, err := tc.Hasher.Verifier(tc.Hash); err != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+			}
+		})
+	}
+
+	t.Run("Noop", func(t *testing.T) {
+		var n NoopHasher
+		hash, _ := n.Hash(pwd)
+
+		verify, err := n.Verifier(hash)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if !verify(pwd) || verify([]byte("wrong")) {
+			t.Errorf("expected only the password to be valid")
+		}
+	})
+}
+
+func TestVerifyTimingSafe(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hash := mustHash(t, pwd)
+	budget := 50 * time.Millisecond
+
+	testCases := []struct {
+		Name  string
+		Pwd   []byte
+		Hash  []byte
+		Valid bool
+	}{
+		{Name: "Valid", Pwd: pwd, Hash: hash, Valid: true},
+		{Name: "Mismatch", Pwd: []byte("wrong"), Hash: hash, Valid: false},
+		{Name: "Invalid Format", Pwd: pwd, Hash: []byte{0x23}, Valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			start := time.Now()
+			if ok := defaultHasher.VerifyTimingSafe(tc.Pwd, tc.Hash, budget); ok != tc.Valid {
+				t.Errorf("expected '%v' but got '%v'", tc.Valid, ok)
+			}
+
+			if elapsed := time.Since(start); elapsed < budget {
+				t.Errorf("expected the call to take at least %v, but took %v", budget, elapsed)
+			}
+		})
+	}
+}
+
+func TestVerifyContext(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(5000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash, _ := hasher.Hash(pwd)
+
+	ok, err := hasher.VerifyContext(context.Background(), pwd, hash)
+	if !ok || err != nil {
+		t.Errorf("expected hash to be valid, but got '%v'", err)
+	}
+
+	t.Run("Interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ok, err := hasher.VerifyContext(ctx, pwd, hash)
+		if ok {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		var interrupted *InterruptedError
+		if !errors.As(err, &interrupted) {
+			t.Errorf("expected an *InterruptedError but got '%v'", err)
+			return
+		}
+
+		if interrupted.Completed != progressInterval || interrupted.Total != 5000 {
+			t.Errorf("expected '%d' of '5000' iterations but got '%d' of '%d'", progressInterval, interrupted.Completed, interrupted.Total)
+		}
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected '%v' but got '%v'", context.Canceled, err)
+		}
+	})
+}
+
+func TestOutputLenVersion(t *testing.T) {
+	if l := OutputLenVersion(1, DefaultSaltSize, DefaultKeySize); l != 13+16+32 {
+		t.Errorf("expected an output length of %d, but got %d", 13+16+32, l)
+	}
+
+	if l := OutputLenVersion(2, DefaultSaltSize, DefaultKeySize); l != 15+16+32 {
+		t.Errorf("expected an output length of %d, but got %d", 15+16+32, l)
+	}
+
+	if l := OutputLenVersion(237, DefaultSaltSize, DefaultKeySize); l != 0 {
+		t.Errorf("expected an output length of 0, but got %d", l)
+	}
+}
+
+func TestOutputLen(t *testing.T) {
+	sizes := [][2]int{
+		{DefaultSaltSize, DefaultKeySize},
+		{64, 128},
+		{256, 512},
+	}
+
+	for _, s := range sizes {
+		hasher, _ := New(DefaultIterationCount, s[0], s[1], DefaultHashKey)
+		hash, _ := hasher.Hash([]byte("MyTestPassword"))
+
+		if l := OutputLen(s[0], s[1]); l != len(hash) {
+			t.Errorf("expected an output length of %d, but got %d", len(hash), l)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(5000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+	hash, _ := hasher.Hash(pwd)
+	if hasher.NeedsRehash(hash) {
+		t.Errorf("didn't expect hash to need rehashing")
+	}
+
+	// stronger parameters don't need rehashing.
+	strong, _ := New(10000, 256, 512, DefaultHashKey)
+	hash, _ = strong.Hash(pwd)
+	if hasher.NeedsRehash(hash) {
+		t.Errorf("didn't expect hash to need rehashing")
+	}
+
+	weaker := map[string]Hasher{}
+	weaker["Iterations"], _ = New(1000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	weaker["Salt Size"], _ = New(5000, 64, DefaultKeySize, DefaultHashKey)
+	weaker["Key Size"], _ = New(5000, DefaultSaltSize, 128, DefaultHashKey)
+	weaker["Algorithm"], _ = New(5000, DefaultSaltSize, DefaultKeySize, HashSHA512)
+	weaker["Pre-Hash"], _ = New(5000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithPreHash(HashSHA512))
+
+	for name, h := range weaker {
+		t.Run(name, func(t *testing.T) {
+			hash, _ := h.Hash(pwd)
+			if !hasher.NeedsRehash(hash) {
+				t.Errorf("expected hash to need rehashing")
+			}
+		})
+	}
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if !hasher.NeedsRehash([]byte{0x23}) {
+			t.Errorf("expected hash to need rehashing")
+		}
+	})
+}
+
+func BenchmarkVerifyTiming(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	hash, err := Hash(pwd)
+	if err != nil {
+		b.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	// the malformed hash has a valid header, but is missing its salt and sub-key.
+	malformed := hash[:headerSizeV2]
+
+	benchmarks := []struct {
+		Name string
+		Pwd  []byte
+		Hash []byte
+	}{
+		{Name: "Correct Password", Pwd: pwd, Hash: hash},
+		{Name: "Wrong Password", Pwd: []byte("WrongPassword"), Hash: hash},
+		{Name: "Malformed Hash", Pwd: pwd, Hash: malformed},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Verify(bm.Pwd, bm.Hash)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	hash, _ := Hash(pwd)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Verify(pwd, hash)
+	}
+}
+
+func BenchmarkHash(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Hash(pwd)
+	}
+}
+
+func BenchmarkHashAppend(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	buf := make([]byte, 0, 256)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, _ = h.HashAppend(buf[:0], pwd)
+	}
+}
+
+func BenchmarkVerifyMismatch(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	hash, _ := Hash(pwd)
+
+	// corrupt a single byte of the stored sub-key, so the derived key differs
+	// from it in either the first or the last byte.
+	mismatched := func(offset int) []byte {
+		out := append([]byte{}, hash...)
+		out[offset] ^= 0xFF
+
+		return out
+	}
+
+	benchmarks := []struct {
+		Name string
+		Hash []byte
+	}{
+		{Name: "First Byte", Hash: mismatched(len(hash) - DefaultKeySize/8)},
+		{Name: "Last Byte", Hash: mismatched(len(hash) - 1)},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Verify(pwd, bm.Hash)
+			}
+		})
+	}
 }
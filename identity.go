@@ -0,0 +1,54 @@
+package hasher
+
+import (
+	"context"
+	"crypto/sha256"
+)
+
+// HashWithIdentity hashes the given password in the same way as Hash, binding the hash
+// to the identity of the account it belongs to, such as a lowercased username, so the
+// hash isn't valid for any other account, for example, if it's copied into another
+// account's row. The identity must be given to VerifyWithIdentity to verify the hash.
+//
+// The identity isn't stored, and the random salt is still used. Instead, the SHA-256
+// digest of the identity is appended to the salt given to pbkdf2, following the context,
+// if any, see WithContext, and the hash's header records that an identity was used.
+// Identities are compared byte-for-byte, so normalize them, such as by case-folding,
+// before hashing and verifying.
+//
+// A non-nil error will be returned if a salt could not be generated, ErrInvalidIdentity
+// if the identity is empty, or ErrInvalidFormatVersion if the hasher produces version 1
+// hashes, which can't record an identity.
+func (h *hasher) HashWithIdentity(pwd, identity []byte) ([]byte, error) {
+	if len(identity) == 0 {
+		return nil, ErrInvalidIdentity
+	}
+
+	if h.version == 1 {
+		return nil, ErrInvalidFormatVersion
+	}
+
+	return h.hash(context.Background(), nil, pwd, identity, nil)
+}
+
+// VerifyWithIdentity verifies the password against a hash produced by HashWithIdentity,
+// in the same way as Verify, returning true only if the identity is the one the hash was
+// produced with. Hashes produced without an identity never match, just as hashes produced
+// with one never match Verify. Results aren't cached, see WithVerifyCache.
+func (h *hasher) VerifyWithIdentity(pwd, hash, identity []byte) bool {
+	if len(identity) == 0 {
+		return false
+	}
+
+	release, _ := h.acquire(context.Background())
+	defer release()
+
+	return h.verifyWith(context.Background(), pwd, identity, hash, nil, nil) == nil
+}
+
+// returns the password bound to the identity, as stored by NoopHasher, which is the
+// SHA-256 digest of the identity, followed by the password.
+func noopIdentity(pwd, identity []byte) []byte {
+	digest := sha256.Sum256(identity)
+	return append(digest[:], pwd...)
+}
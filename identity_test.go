@@ -0,0 +1,86 @@
+package hasher
+
+import (
+	"testing"
+)
+
+func TestHashWithIdentity(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	alice, bob := []byte("alice"), []byte("bob")
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash, err := h.HashWithIdentity(pwd, alice)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if info, _ := Inspect(hash); !info.Identity {
+		t.Errorf("expected the identity to be recorded in the header")
+	}
+
+	testCases := []struct {
+		Name     string
+		Pwd      []byte
+		Identity []byte
+		Expected bool
+	}{
+		{Name: "Same Identity", Pwd: pwd, Identity: alice, Expected: true},
+		{Name: "Other Identity", Pwd: pwd, Identity: bob, Expected: false},
+		{Name: "Wrong Password", Pwd: []byte("wrong"), Identity: alice, Expected: false},
+		{Name: "No Identity", Pwd: pwd, Identity: nil, Expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if ok := h.VerifyWithIdentity(tc.Pwd, hash, tc.Identity); ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+
+	t.Run("Verify", func(t *testing.T) {
+		if err := h.VerifyWithReason(pwd, hash); err != ErrIdentityMismatch {
+			t.Errorf("expected '%v' but got '%v'", ErrIdentityMismatch, err)
+		}
+
+		if h.VerifyWithIdentity(pwd, mustHashWith(t, h, pwd), alice) {
+			t.Errorf("expected a hash without an identity not to match")
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		s, err := encodeString(hash)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		decoded, err := decodeString(s)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if !h.VerifyWithIdentity(pwd, decoded, alice) {
+			t.Errorf("expected the decoded hash to be valid")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := h.HashWithIdentity(pwd, nil); err != ErrInvalidIdentity {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidIdentity, err)
+		}
+
+		v1, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithOutputFormatVersion(1))
+		if _, err := v1.HashWithIdentity(pwd, alice); err != ErrInvalidFormatVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormatVersion, err)
+		}
+	})
+
+	t.Run("Noop", func(t *testing.T) {
+		var n NoopHasher
+		hash, _ := n.HashWithIdentity(pwd, alice)
+
+		if !n.VerifyWithIdentity(pwd, hash, alice) || n.VerifyWithIdentity(pwd, hash, bob) || n.Verify(pwd, hash) {
+			t.Errorf("expected the hash to only be valid with its identity")
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package hasher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// progressInterval is the number of iterations completed between each
+// call to a progress callback.
+const progressInterval = 1000
+
+// DeriveKey derives a key of keyLen bytes from the given password and salt,
+// using the pbkdf2 key derivation algorithm, with the given iteration count
+// and hash key.
+//
+// Unlike New, keyLen is recognised as a number of bytes. If the hash key is
+// HashBcryptPBKDF, the key is derived using bcrypt_pbkdf instead, with the
+// iteration count as the number of rounds.
+//
+// A non-nil error will be returned if any of the values are invalid.
+func DeriveKey(pwd, salt []byte, iterCnt, keyLen, hashKey int) ([]byte, error) {
+	if hashKey == HashBcryptPBKDF {
+		return bcryptPBKDF(pwd, salt, iterCnt, keyLen)
+	}
+
+	if iterCnt < 1 {
+		return nil, ErrInvalidIterationCount
+	}
+
+	if keyLen < 1 {
+		return nil, ErrInvalidKeyLength
+	}
+
+	hashFunc, ok := lookupAlg(hashKey)
+	if !ok {
+		return nil, ErrUnsupportedHashKey
+	}
+
+	if !validKeyLen(keyLen, hashFunc) {
+		return nil, ErrKeyTooLarge
+	}
+
+	return pbkdf2.Key(pwd, salt, iterCnt, keyLen, hashFunc), nil
+}
+
+// maxBlocks is the maximum number of blocks pbkdf2 can derive, as the block
+// index is encoded as a 32-bit integer, see RFC 8018, section 5.2.
+const maxBlocks = 1<<32 - 1
+
+// returns a flag which determines whether or not a key of keyLen bytes can be
+// derived using pbkdf2 with the given hash function, without exceeding the
+// limit of (2^32 - 1) * hLen, where hLen is the hash function's digest size.
+func validKeyLen(keyLen int, hashFunc func() hash.Hash) bool {
+	return uint64(keyLen) <= maxBlocks*uint64(hashFunc().Size())
+}
+
+// VerifyHeaderless verifies the password against a sub-key which was not
+// hashed using the Hash() function, i.e. stored without a header, using
+// the externally-supplied salt, iteration count and hash key. This includes
+// HashBcryptPBKDF, for verifying passphrases against keys derived by OpenSSH.
+//
+// Will return false if the password doesn't match, or any of the
+// parameters are invalid.
+func VerifyHeaderless(pwd, salt, key []byte, iterCnt, hashKey int) bool {
+	actual, err := DeriveKey(pwd, salt, iterCnt, len(key), hashKey)
+	if err != nil {
+		return false
+	}
+
+	return ConstantTimeEqual(actual, key)
+}
+
+// VerifyInferAlgorithm verifies the password against a sub-key stored without a header,
+// in the same way as VerifyHeaderless, for legacy formats which don't record the algorithm,
+// but imply it by the sub-key's length. The algorithm is inferred from len(key):
+//
+//   - 32 bytes is HashSHA256,
+//   - 64 bytes is HashSHA512.
+//
+// Any other length is ambiguous, as it could have been derived, or truncated, using either
+// algorithm, so isn't guessed. A mismatched password returns false, and a nil error.
+//
+// A non-nil error, ErrAmbiguousKeyLength, will be returned if the algorithm can't be
+// inferred, or another error, as returned by DeriveKey, if any parameter is invalid.
+func VerifyInferAlgorithm(pwd, salt, key []byte, iterCnt int) (bool, error) {
+	var hashKey int
+	switch len(key) {
+	case sha256.Size:
+		hashKey = HashSHA256
+	case sha512.Size:
+		hashKey = HashSHA512
+	default:
+		return false, ErrAmbiguousKeyLength
+	}
+
+	actual, err := DeriveKey(pwd, salt, iterCnt, len(key), hashKey)
+	if err != nil {
+		return false, err
+	}
+
+	return ConstantTimeEqual(actual, key), nil
+}
+
+// deriveKeysInfo is the prefix of the HKDF info used by DeriveKeys,
+// which is followed by the index of the key.
+const deriveKeysInfo = "adaptive-password-hasher/derive-keys/"
+
+// DeriveKeys derives multiple independent keys, of the given sizes in bytes,
+// from the password and salt. A single master key is derived using pbkdf2, with
+// the hasher's algorithm, iteration count and key size, so the expensive
+// derivation is only performed once, regardless of the number of keys.
+//
+// Each key is then expanded from the master key using HKDF-Expand, with the
+// info string "adaptive-password-hasher/derive-keys/<index>", where index is the
+// position of the key's size in sizes. The distinct info strings mean each key
+// is cryptographically separated from the others, e.g. knowing an encryption
+// key reveals nothing about an authentication key derived alongside it.
+//
+// Pre-hashing and key truncation options do not apply. Each size must be positive,
+// otherwise ErrInvalidKeyLength is returned, and at most 255 times the digest size of
+// the hasher's algorithm, the limit of HKDF-Expand, otherwise ErrExpandTooLarge is.
+func (h *hasher) DeriveKeys(pwd, salt []byte, sizes ...int) ([][]byte, error) {
+	hashFunc := alg(h.hashKey)
+
+	maxSize := 255 * hashFunc().Size()
+	for _, size := range sizes {
+		if size < 1 {
+			return nil, ErrInvalidKeyLength
+		}
+
+		if size > maxSize {
+			return nil, ErrExpandTooLarge
+		}
+	}
+
+	master := deriveKey(pwd, salt, h.iterCnt, h.keySize, hashFunc, nil)
+
+	keys := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		info := []byte(deriveKeysInfo + strconv.Itoa(i))
+		keys[i] = make([]byte, size)
+
+		if _, err := io.ReadFull(hkdf.Expand(hashFunc, master, info), keys[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// sessionKeyInfo is the HKDF info used by VerifyAndDeriveKey.
+const sessionKeyInfo = "adaptive-password-hasher/session-key"
+
+// VerifyAndDeriveKey verifies the password against the hash, in the same way as Verify,
+// and if the password matches, derives a session key of extraKeyLen bytes, for protocols
+// which encrypt a session with a key derived from the password, once it's authenticated.
+//
+// The session key is expanded using HKDF, with the info string
+// "adaptive-password-hasher/session-key", from the pbkdf2 output block following those
+// of the stored sub-key, which is derived with the hash's salt and iteration count, but
+// never stored. So, like the sub-key, each password guess costs the full iteration count,
+// even given the stored hash, and knowing the session key reveals nothing about the
+// sub-key. Deriving the extra block takes as long as deriving a sub-key of a single block.
+//
+// Will return false, and a nil session key, if the password doesn't match, or extraKeyLen
+// is not positive, or greater than 255 times the digest size of the hash's algorithm.
+func (h *hasher) VerifyAndDeriveKey(pwd, hash []byte, extraKeyLen int) (ok bool, sessionKey []byte) {
+	if extraKeyLen < 1 {
+		return false, nil
+	}
+
+	release, _ := h.acquire(context.Background())
+	defer release()
+
+	err := h.verifyWith(context.Background(), pwd, nil, hash, nil, func(hdr header, pwd, salt, subKey []byte) error {
+		hashFunc := alg(hdr.hashKey)
+		hashLen := hashFunc().Size()
+		if extraKeyLen > 255*hashLen {
+			return ErrExpandTooLarge
+		}
+
+		// the first block which isn't, even in part, stored in the hash.
+		block := deriveBlock(pwd, salt, hdr.iterCnt, (len(subKey)+hashLen-1)/hashLen+1, hashFunc)
+		defer wipe(block)
+
+		sessionKey = make([]byte, extraKeyLen)
+		_, err := io.ReadFull(hkdf.Expand(hashFunc, block, []byte(sessionKeyInfo)), sessionKey)
+
+		return err
+	})
+	if err != nil {
+		return false, nil
+	}
+
+	return true, sessionKey
+}
+
+// keyPool pools the buffers sub-keys are derived into when verifying, which never
+// escape, so each verification doesn't allocate a new one.
+var keyPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
+}
+
+// returns a buffer from the pool.
+func getKeyBuffer() *[]byte {
+	return keyPool.Get().(*[]byte)
+}
+
+// wipes the buffer, so no derived key is left in memory, and returns it to the pool.
+func putKeyBuffer(buf *[]byte) {
+	wipe((*buf)[:cap(*buf)])
+	keyPool.Put(buf)
+}
+
+// InterruptedError is returned when a derivation is stopped part of the way through,
+// because its context is done, for example, by VerifyContext. The number of iterations
+// completed can be used to tune context deadlines, as a fraction of the total.
+type InterruptedError struct {
+	// Completed is the number of iterations completed before the derivation stopped,
+	// out of Total, which is the iteration count multiplied by the number of blocks.
+	Completed int
+	Total     int
+
+	// Err is the context's error.
+	Err error
+}
+
+// Error returns a description of the error, including the progress made.
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("hasher: derivation interrupted after %d of %d iterations: %v", e.Completed, e.Total, e.Err)
+}
+
+// Unwrap returns the context's error, so it can be matched using errors.Is.
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// deriveKey derives a key of keyLen bytes using pbkdf2, producing the same output
+// as pbkdf2.Key. The iterations are performed manually, so that progress can be
+// reported to the given callback every progressInterval iterations, and once all
+// iterations are complete. progress may be nil.
+//
+// As each block of the key is derived separately, the total number of iterations
+// is the iteration count multiplied by the number of blocks.
+func deriveKey(pwd, salt []byte, iterCnt, keyLen int, h func() hash.Hash, progress func(done, total int)) []byte {
+	// this can't fail, as the background context is never done.
+	key, _ := deriveKeyContext(context.Background(), nil, pwd, salt, iterCnt, keyLen, h, progress)
+
+	return key
+}
+
+// derives the given block of a pbkdf2 key alone, T_block in RFC 8018, section 5.2,
+// which is the same as the block's bytes in a key derived by deriveKey.
+func deriveBlock(pwd, salt []byte, iterCnt, block int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, pwd)
+	prf.Write(salt)
+	prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+
+	t := prf.Sum(nil)
+	u := append([]byte{}, t...)
+
+	for n := 2; n <= iterCnt; n++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+
+		for i := range u {
+			t[i] ^= u[i]
+		}
+	}
+
+	wipe(u)
+
+	return t
+}
+
+// deriveKeyContext derives a key in the same way as deriveKey, but also checks the
+// context every progressInterval iterations, stopping the derivation with a non-nil
+// *InterruptedError if it's done.
+//
+// If buf is non-nil, the key is derived into it, growing it if it's too small, so
+// buffers can be reused. The intermediate values are also written to buf.
+func deriveKeyContext(ctx context.Context, buf *[]byte, pwd, salt []byte, iterCnt, keyLen int, h func() hash.Hash, progress func(done, total int)) ([]byte, error) {
+	prf := hmac.New(h, pwd)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+	total := numBlocks * iterCnt
+	done := 0
+
+	step := func() error {
+		done++
+		if done%progressInterval != 0 && done != total {
+			return nil
+		}
+
+		if progress != nil {
+			progress(done, total)
+		}
+
+		if err := ctx.Err(); err != nil && done != total {
+			return &InterruptedError{Completed: done, Total: total, Err: err}
+		}
+
+		return nil
+	}
+
+	// the key is followed by space for the intermediate U values.
+	size := numBlocks*hashLen + hashLen
+	if buf == nil {
+		buf = new([]byte)
+	}
+
+	if cap(*buf) < size {
+		*buf = make([]byte, 0, size)
+	}
+
+	dk := (*buf)[: 0 : size-hashLen]
+	u := (*buf)[size-hashLen : size]
+
+	var cnt [4]byte
+
+	for block := 1; block <= numBlocks; block++ {
+		// T_block = U_1 ^ U_2 ^ ... ^ U_iterCnt, where
+		// U_1 = PRF(pwd, salt || INT(block)) and U_n = PRF(pwd, U_n-1).
+		prf.Reset()
+		prf.Write(salt)
+		cnt[0] = byte(block >> 24)
+		cnt[1] = byte(block >> 16)
+		cnt[2] = byte(block >> 8)
+		cnt[3] = byte(block)
+		prf.Write(cnt[:4])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		if err := step(); err != nil {
+			return nil, err
+		}
+
+		for n := 2; n <= iterCnt; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for i := range u {
+				t[i] ^= u[i]
+			}
+
+			if err := step(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return dk[:keyLen], nil
+}
@@ -0,0 +1,410 @@
+package hasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestDeriveKey(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vector, for P = "password", S = "salt", c = 4096.
+	expected := "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"
+
+	key, err := DeriveKey([]byte("password"), []byte("salt"), 4096, 32, HashSHA256)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if hex.EncodeToString(key) != expected {
+		t.Errorf("expected '%s' but got '%x'", expected, key)
+	}
+
+	t.Run("Invalid Iteration Count", func(t *testing.T) {
+		_, err := DeriveKey([]byte("password"), []byte("salt"), 0, 32, HashSHA256)
+		if err != ErrInvalidIterationCount {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidIterationCount, err)
+		}
+	})
+
+	t.Run("Invalid Key Length", func(t *testing.T) {
+		_, err := DeriveKey([]byte("password"), []byte("salt"), 1, 0, HashSHA256)
+		if err != ErrInvalidKeyLength {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidKeyLength, err)
+		}
+	})
+
+	t.Run("Unsupported Hash Key", func(t *testing.T) {
+		_, err := DeriveKey([]byte("password"), []byte("salt"), 1, 32, 237)
+		if err != ErrUnsupportedHashKey {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedHashKey, err)
+		}
+	})
+}
+
+func TestVerifyHeaderless(t *testing.T) {
+	pwd := []byte("password")
+	salt := []byte("salt")
+	key, _ := hex.DecodeString("c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a")
+
+	if !VerifyHeaderless(pwd, salt, key, 4096, HashSHA256) {
+		t.Errorf("expected key to be valid")
+	}
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		if VerifyHeaderless([]byte("wrong"), salt, key, 4096, HashSHA256) {
+			t.Errorf("expected key to be invalid")
+		}
+	})
+
+	t.Run("Wrong Parameters", func(t *testing.T) {
+		if VerifyHeaderless(pwd, salt, key, 1000, HashSHA256) {
+			t.Errorf("expected key to be invalid")
+		}
+
+		if VerifyHeaderless(pwd, salt, key, 4096, HashSHA512) {
+			t.Errorf("expected key to be invalid")
+		}
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		if VerifyHeaderless(pwd, salt, []byte{}, 4096, HashSHA256) {
+			t.Errorf("expected key to be invalid")
+		}
+
+		if VerifyHeaderless(pwd, salt, key, 4096, 237) {
+			t.Errorf("expected key to be invalid")
+		}
+	})
+}
+
+func TestVerifyInferAlgorithm(t *testing.T) {
+	pwd := []byte("password")
+	salt := []byte("salt")
+	sha256Key, _ := hex.DecodeString("c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a")
+	sha512Key, _ := hex.DecodeString("d197b1b33db0143e018b12f3d1d1479e6cdebdcc97c5c0f87f6902e072f457b5" +
+		"143f30602641b3d55cd335988cb36b84376060ecd532e039b742a239434af2d5")
+
+	testCases := []struct {
+		Name        string
+		Pwd         []byte
+		Key         []byte
+		IterCnt     int
+		Expected    bool
+		ExpectedErr error
+	}{
+		{"SHA256", pwd, sha256Key, 4096, true, nil},
+		{"SHA512", pwd, sha512Key, 4096, true, nil},
+		{"Wrong Password", []byte("wrong"), sha512Key, 4096, false, nil},
+		{"Wrong Iterations", pwd, sha256Key, 1000, false, nil},
+		{"Ambiguous Length", pwd, sha512Key[:48], 4096, false, ErrAmbiguousKeyLength},
+		{"Empty Key", pwd, nil, 4096, false, ErrAmbiguousKeyLength},
+		{"Invalid Iterations", pwd, sha256Key, 0, false, ErrInvalidIterationCount},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := VerifyInferAlgorithm(tc.Pwd, salt, tc.Key, tc.IterCnt)
+			if err != tc.ExpectedErr {
+				t.Errorf("expected '%v' but got '%v'", tc.ExpectedErr, err)
+			}
+
+			if ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+}
+
+func TestManualDeriveKey(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	salt := []byte("MyTestSalt")
+
+	for _, hashKey := range []int{HashSHA256, HashSHA512} {
+		for _, keyLen := range []int{1, 16, 32, 64, 100} {
+			for _, iterCnt := range []int{1, 2, 1000, 2500} {
+				expected := pbkdf2.Key(pwd, salt, iterCnt, keyLen, alg(hashKey))
+				actual := deriveKey(pwd, salt, iterCnt, keyLen, alg(hashKey), nil)
+
+				if !bytes.Equal(actual, expected) {
+					t.Errorf("expected '%x' but got '%x' (hash key: %d, key length: %d, iterations: %d)",
+						expected, actual, hashKey, keyLen, iterCnt)
+				}
+			}
+		}
+	}
+}
+
+func TestHashWithProgress(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(2500, DefaultSaltSize, DefaultKeySize, HashSHA256)
+
+	var calls [][2]int
+	hash, err := hasher.HashWithProgress(pwd, func(done, total int) {
+		calls = append(calls, [2]int{done, total})
+	})
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	t.Run("Callback", func(t *testing.T) {
+		expected := [][2]int{{1000, 2500}, {2000, 2500}, {2500, 2500}}
+		if len(calls) != len(expected) {
+			t.Errorf("expected %d calls, but got %d", len(expected), len(calls))
+			return
+		}
+
+		for i, c := range calls {
+			if c != expected[i] {
+				t.Errorf("expected call %d to be %v, but got %v", i, expected[i], c)
+			}
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !hasher.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+}
+
+func TestDeriveKeys(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	salt := []byte("MyTestSalt")
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+	keys, err := hasher.DeriveKeys(pwd, salt, 32, 32, 16)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	t.Run("Sizes", func(t *testing.T) {
+		for i, size := range []int{32, 32, 16} {
+			if len(keys[i]) != size {
+				t.Errorf("expected key %d to be %d bytes, but got %d", i, size, len(keys[i]))
+			}
+		}
+	})
+
+	t.Run("Independent", func(t *testing.T) {
+		if bytes.Equal(keys[0], keys[1]) {
+			t.Errorf("expected keys of the same size to differ")
+		}
+
+		if bytes.Equal(keys[0][:16], keys[2]) {
+			t.Errorf("expected keys not to share a prefix")
+		}
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		again, _ := hasher.DeriveKeys(pwd, salt, 32)
+		if !bytes.Equal(keys[0], again[0]) {
+			t.Errorf("expected the same key to be derived")
+		}
+
+		other, _ := hasher.DeriveKeys([]byte("NotMyPassword"), salt, 32)
+		if bytes.Equal(keys[0], other[0]) {
+			t.Errorf("expected a different key to be derived")
+		}
+	})
+
+	t.Run("Invalid Size", func(t *testing.T) {
+		for _, size := range []int{0, -1} {
+			_, err := hasher.DeriveKeys(pwd, salt, size)
+			if err != ErrInvalidKeyLength {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidKeyLength, err)
+			}
+		}
+	})
+
+	t.Run("Oversize", func(t *testing.T) {
+		if _, err := hasher.DeriveKeys(pwd, salt, 255*32); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		_, err := hasher.DeriveKeys(pwd, salt, 255*32+1)
+		if err != ErrExpandTooLarge {
+			t.Errorf("expected '%v' but got '%v'", ErrExpandTooLarge, err)
+		}
+	})
+}
+
+func TestVerifyAndDeriveKey(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hash := mustHash(t, pwd)
+
+	ok, key := defaultHasher.VerifyAndDeriveKey(pwd, hash, 32)
+	if !ok {
+		t.Fatalf("expected hash to be valid")
+	}
+
+	if len(key) != 32 {
+		t.Errorf("expected a 32 byte key, but got %d", len(key))
+	}
+
+	t.Run("Deterministic", func(t *testing.T) {
+		_, again := defaultHasher.VerifyAndDeriveKey(pwd, hash, 32)
+		if !bytes.Equal(key, again) {
+			t.Errorf("expected the same key for the same password and hash")
+		}
+
+		_, other := defaultHasher.VerifyAndDeriveKey(pwd, mustHash(t, pwd), 32)
+		if bytes.Equal(key, other) {
+			t.Errorf("expected a different key for a different salt")
+		}
+	})
+
+	t.Run("Stretched", func(t *testing.T) {
+		hdr, err := scanHeader(hash)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		salt, subKey := hash[hdr.size:hdr.size+hdr.saltLen], hdr.subKey(hash)
+
+		// without the iterations, the key can't be recomputed from what's stored.
+		for name, secret := range map[string][]byte{
+			"Password":           pwd,
+			"Sub-Key":            subKey,
+			"Password Salt":      append(append([]byte{}, pwd...), salt...),
+			"Single Iteration":   deriveBlock(pwd, salt, 1, 2, sha256.New),
+			"Stored Block":       deriveBlock(pwd, salt, hdr.iterCnt, 1, sha256.New),
+			"Password As Secret": pbkdf2.Key(pwd, subKey, 1, 32, sha256.New),
+		} {
+			for _, derived := range [][]byte{
+				expand(t, hkdf.Expand(sha256.New, secret, []byte(sessionKeyInfo)), 32),
+				expand(t, hkdf.New(sha256.New, secret, subKey, []byte(sessionKeyInfo)), 32),
+				expand(t, hkdf.New(sha256.New, secret, salt, []byte(sessionKeyInfo)), 32),
+			} {
+				if bytes.Equal(key, derived) {
+					t.Errorf("%s: expected the key not to be derived without the iterations", name)
+				}
+			}
+		}
+
+		// but it is the block following the sub-key's, derived with every iteration.
+		block := deriveBlock(pwd, salt, hdr.iterCnt, 2, sha256.New)
+		if expected := expand(t, hkdf.Expand(sha256.New, block, []byte(sessionKeyInfo)), 32); !bytes.Equal(key, expected) {
+			t.Errorf("expected '%x' but got '%x'", expected, key)
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		if ok, key := defaultHasher.VerifyAndDeriveKey([]byte("wrong"), hash, 32); ok || key != nil {
+			t.Errorf("expected (false, nil) but got (%v, %x)", ok, key)
+		}
+	})
+
+	t.Run("Invalid Length", func(t *testing.T) {
+		for _, size := range []int{0, -1, 255*32 + 1} {
+			if ok, key := defaultHasher.VerifyAndDeriveKey(pwd, hash, size); ok || key != nil {
+				t.Errorf("%d: expected (false, nil) but got (%v, %x)", size, ok, key)
+			}
+		}
+	})
+}
+
+// reads n bytes from an HKDF reader.
+func expand(t *testing.T, r io.Reader, n int) []byte {
+	t.Helper()
+
+	out := make([]byte, n)
+	if _, err := io.ReadFull(r, out); err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	return out
+}
+
+func TestDeriveBlock(t *testing.T) {
+	pwd, salt := []byte("MyTestPassword"), []byte("MyTestSalt")
+	key := pbkdf2.Key(pwd, salt, 100, 3*sha256.Size, sha256.New)
+
+	for block := 1; block <= 3; block++ {
+		expected := key[(block-1)*sha256.Size : block*sha256.Size]
+		if actual := deriveBlock(pwd, salt, 100, block, sha256.New); !bytes.Equal(actual, expected) {
+			t.Errorf("block %d: expected '%x' but got '%x'", block, expected, actual)
+		}
+	}
+}
+
+func TestKeyTooLarge(t *testing.T) {
+	if strconv.IntSize < 64 {
+		t.Skip("the limit can't be exceeded by an int on this platform")
+	}
+
+	for _, hashKey := range []int{HashSHA256, HashSHA512} {
+		hashFunc := alg(hashKey)
+		limit := int(uint64(maxBlocks) * uint64(hashFunc().Size()))
+
+		if !validKeyLen(limit, hashFunc) {
+			t.Errorf("expected a key length of %d to be valid", limit)
+		}
+
+		if validKeyLen(limit+1, hashFunc) {
+			t.Errorf("expected a key length of %d to be invalid", limit+1)
+		}
+
+		_, err := DeriveKey([]byte("password"), []byte("salt"), 1, limit+1, hashKey)
+		if err != ErrKeyTooLarge {
+			t.Errorf("expected '%v' but got '%v'", ErrKeyTooLarge, err)
+		}
+
+		_, err = New(DefaultIterationCount, DefaultSaltSize, (limit+1)*8, hashKey)
+		if !errors.Is(err, ErrKeyTooLarge) {
+			t.Errorf("expected '%v' but got '%v'", ErrKeyTooLarge, err)
+		}
+	}
+}
+
+// kdfBenchmarks are the KDFs supported by DeriveKey, with parameters of roughly
+// equivalent cost. Adding a KDF here includes it in BenchmarkKDFs.
+var kdfBenchmarks = []struct {
+	Name    string
+	HashKey int
+	IterCnt int
+}{
+	{Name: "PBKDF2-SHA256", HashKey: HashSHA256, IterCnt: 100000},
+	{Name: "PBKDF2-SHA512", HashKey: HashSHA512, IterCnt: 100000},
+	{Name: "bcrypt_pbkdf", HashKey: HashBcryptPBKDF, IterCnt: 16},
+}
+
+func BenchmarkKDFs(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	salt := []byte("MyTestSalt")
+
+	for _, bm := range kdfBenchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := DeriveKey(pwd, salt, bm.IterCnt, DefaultKeySize/8, bm.HashKey); err != nil {
+					b.Fatalf("didn't expect to get an error: %v", err)
+				}
+			}
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "keys/s")
+		})
+	}
+}
+
+func TestPutKeyBuffer(t *testing.T) {
+	buf := getKeyBuffer()
+	*buf = append((*buf)[:0], 1, 2, 3, 4)
+	b := (*buf)[:cap(*buf)]
+
+	putKeyBuffer(buf)
+
+	for i, v := range b {
+		if v != 0 {
+			t.Errorf("expected byte %d to be wiped but got %d", i, v)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package hasher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encodings of the fields in a Layout.
+const (
+	// EncodingUint8 is a single, unsigned, byte.
+	EncodingUint8 = "uint8"
+
+	// EncodingUint32 is a 4-byte, unsigned, big-endian integer.
+	EncodingUint32 = "uint32be"
+
+	// EncodingInt64 is an 8-byte, signed, big-endian integer, in two's complement.
+	EncodingInt64 = "int64be"
+
+	// EncodingBytes is a run of raw bytes, whose length is given by another field,
+	// or, if there's no such field, is the remainder of the hash.
+	EncodingBytes = "bytes"
+)
+
+// LayoutField describes a single field of the binary hash format, see Layout.
+type LayoutField struct {
+	// Name is the name of the field, such as "iterations".
+	Name string
+
+	// Offset is the byte offset of the field from the start of the hash, or -1 if it
+	// follows an optional field, in which case it immediately follows the last field
+	// present before it.
+	Offset int
+
+	// Size is the number of bytes the field occupies, or 0 if it's variable, see Length.
+	Size int
+
+	// Encoding is how the field is encoded, such as EncodingUint32.
+	Encoding string
+
+	// Flag is the bit which must be set in the "flags" field for the field to be present,
+	// or 0 if it's always present.
+	Flag byte
+
+	// Length is the name of the field whose value is the Size of a variable field, in
+	// bytes. If it's empty, or that field isn't present, the field is the remainder.
+	Length string
+}
+
+// LayoutFlag describes a bit of the "flags" field of the binary hash format, see Layout.
+// Some flags add no field, but still change how the sub-key is derived, so a parser must
+// handle each flag set in a hash, not only the fields it adds.
+type LayoutFlag struct {
+	// Name is the name of the flag, such as "context".
+	Name string
+
+	// Bit is the bit which is set in the "flags" field when the flag is present.
+	Bit byte
+
+	// Field is the name of the field the flag adds, or empty if it adds none.
+	Field string
+
+	// Derivation describes how the flag changes the derivation of the sub-key, or is
+	// empty if it doesn't.
+	Derivation string
+}
+
+// Layout describes the binary format of hashes, in a given format version, as a
+// sequence of fields, and the flags which change them, so it can be used to generate
+// parsers for other languages.
+type Layout struct {
+	Version int
+	Fields  []LayoutField
+	Flags   []LayoutFlag
+}
+
+// BinaryLayout returns the layout of hashes in the format version produced by Hash,
+// HeaderVersion. The fields are in the order they appear in a hash: first, values which
+// are always present, then optional values, in the order of their flags, then the salt
+// and sub-key. All integers are big-endian. Every flag is described, in the order of its
+// bit, including those which add no field.
+func BinaryLayout() Layout {
+	return Layout{
+		Version: HeaderVersion,
+		Fields: []LayoutField{
+			{Name: "magic", Offset: 0, Size: 1, Encoding: EncodingUint8},
+			{Name: "version", Offset: 1, Size: 1, Encoding: EncodingUint8},
+			{Name: "flags", Offset: 2, Size: 1, Encoding: EncodingUint8},
+			{Name: "hashKey", Offset: 3, Size: 4, Encoding: EncodingUint32},
+			{Name: "iterations", Offset: 7, Size: 4, Encoding: EncodingUint32},
+			{Name: "saltLength", Offset: 11, Size: 4, Encoding: EncodingUint32},
+			{Name: "preHashKey", Offset: headerSizeV2, Size: 4, Encoding: EncodingUint32, Flag: flagPreHash},
+			{Name: "createdAt", Offset: -1, Size: 8, Encoding: EncodingInt64, Flag: flagTimestamp},
+			{Name: "keyLength", Offset: -1, Size: 4, Encoding: EncodingUint32, Flag: flagKeyLen},
+			{Name: "outerHashKey", Offset: -1, Size: 4, Encoding: EncodingUint32, Flag: flagOuterHash},
+			{Name: "salt", Offset: -1, Encoding: EncodingBytes, Length: "saltLength"},
+			{Name: "subKey", Offset: -1, Encoding: EncodingBytes, Length: "keyLength"},
+		},
+		Flags: []LayoutFlag{
+			{Name: "preHash", Bit: flagPreHash, Field: "preHashKey", Derivation: "the password is replaced by its digest, using the preHashKey algorithm"},
+			{Name: "timestamp", Bit: flagTimestamp, Field: "createdAt"},
+			{Name: "keyLength", Bit: flagKeyLen, Field: "keyLength"},
+			{Name: "context", Bit: flagContext, Derivation: "the pbkdf2 salt is the salt followed by the context, which isn't stored"},
+			{Name: "outerHash", Bit: flagOuterHash, Field: "outerHashKey", Derivation: "the sub-key is the HMAC of the pbkdf2 key, using the outerHashKey algorithm, keyed by \"" + outerHashKey + "\", truncated to the pbkdf2 key's length"},
+			{Name: "identity", Bit: flagIdentity, Derivation: "the pbkdf2 salt is followed by the SHA-256 digest of the identity, after the context, if any, which isn't stored"},
+		},
+	}
+}
+
+// String returns the layout as a table, with a line for each field, for documentation.
+func (l Layout) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "version %d\n", l.Version)
+
+	for _, f := range l.Fields {
+		offset, size := "...", "var"
+		if f.Offset >= 0 {
+			offset = fmt.Sprint(f.Offset)
+		}
+
+		if f.Size > 0 {
+			size = fmt.Sprint(f.Size)
+		}
+
+		fmt.Fprintf(&sb, "%-4s %-4s %-9s %s", offset, size, f.Encoding, f.Name)
+
+		if f.Flag != 0 {
+			fmt.Fprintf(&sb, ", if flags&0x%02x", f.Flag)
+		}
+
+		if f.Length != "" {
+			fmt.Fprintf(&sb, ", length %s", f.Length)
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	for _, f := range l.Flags {
+		fmt.Fprintf(&sb, "flag 0x%02x %s", f.Bit, f.Name)
+
+		if f.Field != "" {
+			fmt.Fprintf(&sb, ", adds %s", f.Field)
+		}
+
+		if f.Derivation != "" {
+			fmt.Fprintf(&sb, ", %s", f.Derivation)
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,193 @@
+package hasher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// parses the hash using only the layout, as a parser in another language would,
+// returning the integer value of each field present, and the salt and sub-key.
+func parseWithLayout(t *testing.T, l Layout, hash []byte) (values map[string]int64, salt, subKey []byte) {
+	t.Helper()
+
+	values = map[string]int64{}
+	fields := map[string][]byte{}
+
+	offset := 0
+	for _, f := range l.Fields {
+		if f.Flag != 0 && byte(values["flags"])&f.Flag == 0 {
+			continue
+		}
+
+		if f.Offset >= 0 && f.Offset != offset {
+			t.Fatalf("expected '%s' at offset %d but it's at %d", f.Name, f.Offset, offset)
+		}
+
+		size := f.Size
+		if size == 0 {
+			size = len(hash) - offset
+			if n, ok := values[f.Length]; ok {
+				size = int(n)
+			}
+		}
+
+		b := hash[offset : offset+size]
+		fields[f.Name] = b
+		offset += size
+
+		switch f.Encoding {
+		case EncodingUint8:
+			values[f.Name] = int64(b[0])
+		case EncodingUint32:
+			values[f.Name] = int64(binary.BigEndian.Uint32(b))
+		case EncodingInt64:
+			values[f.Name] = int64(binary.BigEndian.Uint64(b))
+		}
+	}
+
+	if offset != len(hash) {
+		t.Fatalf("expected the layout to cover all %d bytes but it covered %d", len(hash), offset)
+	}
+
+	return values, fields["salt"], fields["subKey"]
+}
+
+func TestBinaryLayout(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	plain, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	full, _ := New(5000, 256, 256, HashSHA512,
+		WithPreHash(HashSHA256),
+		WithTimestamp(true),
+		WithKeyLengthInHeader(true),
+		WithContext([]byte("login")),
+		WithOuterHash(HashSHA512),
+	)
+
+	// hashed with an identity, so every known flag is set.
+	fullHash, err := full.HashWithIdentity(pwd, []byte("user@example.com"))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if hdr, _ := scanHeader(fullHash); hdr.flags != knownFlags {
+		t.Fatalf("expected flags '%08b' but got '%08b'", knownFlags, hdr.flags)
+	}
+
+	layout := BinaryLayout()
+	if layout.Version != HeaderVersion {
+		t.Errorf("expected version '%d' but got '%d'", HeaderVersion, layout.Version)
+	}
+
+	testCases := []struct {
+		Name string
+		Hash []byte
+	}{
+		{"No Flags", mustHashWith(t, plain, pwd)},
+		{"All Flags", fullHash},
+	}
+
+	for _, tc := range testCases {
+		hash := tc.Hash
+		info, _ := Inspect(hash)
+
+		t.Run(tc.Name, func(t *testing.T) {
+			values, salt, subKey := parseWithLayout(t, layout, hash)
+
+			if values["magic"] != formatMagic || values["version"] != HeaderVersion {
+				t.Errorf("expected magic '%d' and version '%d' but got '%d' and '%d'", formatMagic, HeaderVersion, values["magic"], values["version"])
+			}
+
+			expected := map[string]int64{
+				"hashKey":    int64(info.Algorithm),
+				"iterations": int64(info.Iterations),
+				"saltLength": int64(info.SaltSize / 8),
+			}
+
+			if info.PreHash != 0 {
+				expected["preHashKey"] = int64(info.PreHash)
+				expected["createdAt"] = info.CreatedAt.Unix()
+				expected["keyLength"] = int64(info.KeySize / 8)
+				expected["outerHashKey"] = int64(info.OuterHash)
+			}
+
+			for name, v := range expected {
+				if values[name] != v {
+					t.Errorf("expected '%s' to be '%d' but got '%d'", name, v, values[name])
+				}
+			}
+
+			hdr, _ := scanHeader(hash)
+			expectedSalt, expectedKey, _ := hdr.components(hash, SaltBeforeKey)
+			if !bytes.Equal(salt, expectedSalt) || !bytes.Equal(subKey, expectedKey) {
+				t.Errorf("expected the salt and sub-key to match the hash's")
+			}
+		})
+	}
+
+	t.Run("Flags", func(t *testing.T) {
+		fields := map[string]LayoutField{}
+		for _, f := range layout.Fields {
+			fields[f.Name] = f
+		}
+
+		var described byte
+		for _, f := range layout.Flags {
+			if described&f.Bit != 0 {
+				t.Errorf("expected '%s' to be the only flag with bit 0x%02x", f.Name, f.Bit)
+			}
+
+			described |= f.Bit
+
+			if f.Field != "" && fields[f.Field].Flag != f.Bit {
+				t.Errorf("expected '%s' to add the '%s' field", f.Name, f.Field)
+			}
+		}
+
+		if described != knownFlags {
+			t.Errorf("expected flags '%08b' to be described but got '%08b'", knownFlags, described)
+		}
+	})
+
+	t.Run("Derivation", func(t *testing.T) {
+		// derives a key from a hash with a context and identity, as a parser following
+		// the layout's flags would.
+		context, identity := []byte("login"), []byte("user@example.com")
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithContext(context))
+
+		hash, err := h.HashWithIdentity(pwd, identity)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		values, salt, subKey := parseWithLayout(t, layout, hash)
+		if values["flags"] != int64(flagContext|flagIdentity) {
+			t.Fatalf("expected flags '%08b' but got '%08b'", flagContext|flagIdentity, values["flags"])
+		}
+
+		digest := sha256.Sum256(identity)
+		pbkdf2Salt := append(append(append([]byte{}, salt...), context...), digest[:]...)
+
+		key, _ := DeriveKey(pwd, pbkdf2Salt, int(values["iterations"]), len(subKey), int(values["hashKey"]))
+		if !bytes.Equal(key, subKey) {
+			t.Errorf("expected '%x' but got '%x'", subKey, key)
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		s := layout.String()
+		for _, f := range layout.Fields {
+			if !strings.Contains(s, f.Name) {
+				t.Errorf("expected '%s' to be described", f.Name)
+			}
+		}
+
+		for _, f := range layout.Flags {
+			if !strings.Contains(s, f.Name) || !strings.Contains(s, f.Derivation) {
+				t.Errorf("expected '%s' to be described", f.Name)
+			}
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package hasher
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Errors returned when verifying modular crypt format strings.
+var (
+	ErrInvalidMCF        = errors.New("string is not in the modular crypt format")
+	ErrInvalidLDAP       = errors.New("value is not in the ldap {SCHEME} format")
+	ErrUnsupportedScheme = errors.New("unsupported scheme")
+)
+
+// mcfSchemes maps the supported scheme ids, as used by passlib, to their hash functions.
+var mcfSchemes = map[string]func() hash.Hash{
+	"pbkdf2":        sha1.New,
+	"pbkdf2-sha256": sha256.New,
+	"pbkdf2-sha512": sha512.New,
+}
+
+// ldapSchemes maps the supported LDAP schemes, as used by passlib's ldap_pbkdf2 hashers
+// and OpenLDAP's pbkdf2 module, to their modular crypt format scheme ids.
+var ldapSchemes = map[string]string{
+	"PBKDF2":        "pbkdf2",
+	"PBKDF2-SHA256": "pbkdf2-sha256",
+	"PBKDF2-SHA512": "pbkdf2-sha512",
+}
+
+// ab64 is passlib's "adapted base64" encoding, which is the standard encoding
+// without padding, using '.' in place of '+'.
+var ab64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+// VerifyMCF verifies the password against a pbkdf2 hash in the modular crypt
+// format, as produced by systems such as passlib, returning a flag which
+// determines whether or not the password matches the hash.
+//
+// The string must be in the format "$id$rounds$salt$checksum", where the salt and
+// checksum are encoded using passlib's adapted base64. The supported scheme ids are
+// "pbkdf2" (SHA1), "pbkdf2-sha256" and "pbkdf2-sha512".
+//
+// A non-nil error will be returned if the string is not in the modular crypt
+// format, or ErrUnsupportedScheme if the scheme id is not recognised.
+func VerifyMCF(pwd []byte, s string) (bool, error) {
+	// the leading '$' results in an empty first field.
+	fields := strings.Split(s, "$")
+	if len(fields) < 2 || fields[0] != "" {
+		return false, ErrInvalidMCF
+	}
+
+	hashFunc, ok := mcfSchemes[fields[1]]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedScheme, fields[1])
+	}
+
+	if len(fields) != 5 {
+		return false, ErrInvalidMCF
+	}
+
+	iterCnt, err := parseIterations(fields[2])
+	if err != nil {
+		return false, ErrInvalidMCF
+	}
+
+	salt, err := ab64.DecodeString(fields[3])
+	if err != nil {
+		return false, ErrInvalidMCF
+	}
+
+	expected, err := ab64.DecodeString(fields[4])
+	if err != nil || len(expected) < 1 {
+		return false, ErrInvalidMCF
+	}
+
+	actual := pbkdf2.Key(pwd, salt, iterCnt, len(expected), hashFunc)
+
+	return ConstantTimeEqual(actual, expected), nil
+}
+
+// VerifyLDAP verifies the password against a pbkdf2 userPassword value from an LDAP
+// directory, such as OpenLDAP, returning a flag which determines whether or not the
+// password matches the hash.
+//
+// The value must be in the format "{SCHEME}rounds$salt$checksum", where the salt and
+// checksum are encoded using passlib's adapted base64, as with VerifyMCF. The supported
+// schemes are "{PBKDF2}" (SHA1), "{PBKDF2-SHA256}" and "{PBKDF2-SHA512}", which are
+// matched case-insensitively.
+//
+// A non-nil error will be returned if the value is not in the expected format, or
+// ErrUnsupportedScheme if the scheme is not recognised.
+func VerifyLDAP(pwd []byte, value string) (bool, error) {
+	end := strings.IndexByte(value, '}')
+	if !strings.HasPrefix(value, "{") || end < 0 {
+		return false, ErrInvalidLDAP
+	}
+
+	scheme := value[1:end]
+	id, ok := ldapSchemes[strings.ToUpper(scheme)]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedScheme, scheme)
+	}
+
+	// the remainder is a modular crypt format string, without its scheme id.
+	ok, err := VerifyMCF(pwd, "$"+id+"$"+value[end+1:])
+	if err == ErrInvalidMCF {
+		return false, ErrInvalidLDAP
+	}
+
+	return ok, err
+}
@@ -0,0 +1,120 @@
+package hasher
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyMCF(t *testing.T) {
+	pwd := []byte("password")
+	hashes := map[string]string{
+		// from the passlib documentation.
+		"SHA256": "$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+		"SHA512": "$pbkdf2-sha512$25000$AQIDBAUGBwgJCgsMDQ4PEA$jv3nKI6q7eb2VSu/eSFVDE2tpFuoIKFvoaEUQHat2VJtYL/UM3fQuyHCRA2Ck6nPt7hTq5MDjWfb6o.j5aM3rQ",
+		"SHA1":   "$pbkdf2$29000$AQIDBAUGBwgJCgsMDQ4PEA$M6S2lxlYw24G7AKgkOBNPMa8Y4k",
+	}
+
+	for name, s := range hashes {
+		t.Run(name, func(t *testing.T) {
+			ok, err := VerifyMCF(pwd, s)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+			}
+
+			if !ok {
+				t.Errorf("expected hash to be valid")
+			}
+
+			ok, _ = VerifyMCF([]byte("NotMyPassword"), s)
+			if ok {
+				t.Errorf("expected hash to be invalid")
+			}
+		})
+	}
+
+	t.Run("Unsupported Scheme", func(t *testing.T) {
+		_, err := VerifyMCF(pwd, "$2b$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW")
+		if !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedScheme, err)
+		}
+	})
+
+	t.Run("Iteration Field", func(t *testing.T) {
+		for _, iter := range []string{"06400", " 6400", "6400 "} {
+			s := "$pbkdf2-sha256$" + iter + "$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M"
+			if ok, err := VerifyMCF(pwd, s); !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v) for %q", ok, err, s)
+			}
+		}
+
+		for _, iter := range []string{"+6400", "-6400", "6.4e3", "0x1900", "6400a"} {
+			s := "$pbkdf2-sha256$" + iter + "$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M"
+			if _, err := VerifyMCF(pwd, s); err != ErrInvalidMCF {
+				t.Errorf("expected '%v' but got '%v' for %q", ErrInvalidMCF, err, s)
+			}
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+			"$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw",
+			"$pbkdf2-sha256$abc$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+			"$pbkdf2-sha256$6400$0Zrz*XitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+			"$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$",
+		} {
+			_, err := VerifyMCF(pwd, s)
+			if err != ErrInvalidMCF {
+				t.Errorf("expected '%v' but got '%v' for %q", ErrInvalidMCF, err, s)
+			}
+		}
+	})
+}
+
+func TestVerifyLDAP(t *testing.T) {
+	pwd := []byte("password")
+	values := map[string]string{
+		// the passlib documentation's hashes, in the ldap format.
+		"SHA256": "{PBKDF2-SHA256}6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+		"SHA512": "{PBKDF2-SHA512}25000$AQIDBAUGBwgJCgsMDQ4PEA$jv3nKI6q7eb2VSu/eSFVDE2tpFuoIKFvoaEUQHat2VJtYL/UM3fQuyHCRA2Ck6nPt7hTq5MDjWfb6o.j5aM3rQ",
+		"SHA1":   "{PBKDF2}29000$AQIDBAUGBwgJCgsMDQ4PEA$M6S2lxlYw24G7AKgkOBNPMa8Y4k",
+		"Case":   "{pbkdf2-sha256}6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+	}
+
+	for name, value := range values {
+		t.Run(name, func(t *testing.T) {
+			ok, err := VerifyLDAP(pwd, value)
+			if !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+			}
+
+			ok, _ = VerifyLDAP([]byte("NotMyPassword"), value)
+			if ok {
+				t.Errorf("expected hash to be invalid")
+			}
+		})
+	}
+
+	t.Run("Unsupported Scheme", func(t *testing.T) {
+		_, err := VerifyLDAP(pwd, "{SSHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=")
+		if !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedScheme, err)
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		for _, value := range []string{
+			"",
+			"PBKDF2-SHA256}6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+			"{PBKDF2-SHA256",
+			"{PBKDF2-SHA256}6400$0ZrzXitFSGltTQnBWOsdAw",
+			"{PBKDF2-SHA256}abc$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+		} {
+			_, err := VerifyLDAP(pwd, value)
+			if err != ErrInvalidLDAP {
+				t.Errorf("expected '%v' but got '%v' for %q", ErrInvalidLDAP, err, value)
+			}
+		}
+	})
+}
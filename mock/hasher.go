@@ -5,8 +5,12 @@
 package mock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
+	hasher "github.com/reecerussell/adaptive-password-hasher"
+	io "io"
 	reflect "reflect"
+	time "time"
 )
 
 // MockHasher is a mock of Hasher interface.
@@ -33,11 +37,12 @@ func (m *MockHasher) EXPECT() *MockHasherMockRecorder {
 }
 
 // Hash mocks base method.
-func (m *MockHasher) Hash(pwd []byte) []byte {
+func (m *MockHasher) Hash(pwd []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Hash", pwd)
 	ret0, _ := ret[0].([]byte)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Hash indicates an expected call of Hash.
@@ -46,6 +51,96 @@ func (mr *MockHasherMockRecorder) Hash(pwd interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockHasher)(nil).Hash), pwd)
 }
 
+// HashAppend mocks base method.
+func (m *MockHasher) HashAppend(dst, pwd []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashAppend", dst, pwd)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashAppend indicates an expected call of HashAppend.
+func (mr *MockHasherMockRecorder) HashAppend(dst, pwd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashAppend", reflect.TypeOf((*MockHasher)(nil).HashAppend), dst, pwd)
+}
+
+// HashWithProgress mocks base method.
+func (m *MockHasher) HashWithProgress(pwd []byte, progress func(int, int)) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashWithProgress", pwd, progress)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashWithProgress indicates an expected call of HashWithProgress.
+func (mr *MockHasherMockRecorder) HashWithProgress(pwd, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashWithProgress", reflect.TypeOf((*MockHasher)(nil).HashWithProgress), pwd, progress)
+}
+
+// HashWithIdentity mocks base method.
+func (m *MockHasher) HashWithIdentity(pwd, identity []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashWithIdentity", pwd, identity)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashWithIdentity indicates an expected call of HashWithIdentity.
+func (mr *MockHasherMockRecorder) HashWithIdentity(pwd, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashWithIdentity", reflect.TypeOf((*MockHasher)(nil).HashWithIdentity), pwd, identity)
+}
+
+// HashContext mocks base method.
+func (m *MockHasher) HashContext(ctx context.Context, pwd []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashContext", ctx, pwd)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashContext indicates an expected call of HashContext.
+func (mr *MockHasherMockRecorder) HashContext(ctx, pwd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashContext", reflect.TypeOf((*MockHasher)(nil).HashContext), ctx, pwd)
+}
+
+// HashString mocks base method.
+func (m *MockHasher) HashString(pwd []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashString", pwd)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashString indicates an expected call of HashString.
+func (mr *MockHasherMockRecorder) HashString(pwd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashString", reflect.TypeOf((*MockHasher)(nil).HashString), pwd)
+}
+
+// HashPasswordReader mocks base method.
+func (m *MockHasher) HashPasswordReader(r io.Reader) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashPasswordReader", r)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HashPasswordReader indicates an expected call of HashPasswordReader.
+func (mr *MockHasherMockRecorder) HashPasswordReader(r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashPasswordReader", reflect.TypeOf((*MockHasher)(nil).HashPasswordReader), r)
+}
+
 // Verify mocks base method.
 func (m *MockHasher) Verify(pwd, hash []byte) bool {
 	m.ctrl.T.Helper()
@@ -59,3 +154,348 @@ func (mr *MockHasherMockRecorder) Verify(pwd, hash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockHasher)(nil).Verify), pwd, hash)
 }
+
+// VerifyWithReason mocks base method.
+func (m *MockHasher) VerifyWithReason(pwd, hash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWithReason", pwd, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyWithReason indicates an expected call of VerifyWithReason.
+func (mr *MockHasherMockRecorder) VerifyWithReason(pwd, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWithReason", reflect.TypeOf((*MockHasher)(nil).VerifyWithReason), pwd, hash)
+}
+
+// VerifyExpectingAlgorithm mocks base method.
+func (m *MockHasher) VerifyExpectingAlgorithm(pwd, hash []byte, expectedAlg int) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyExpectingAlgorithm", pwd, hash, expectedAlg)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyExpectingAlgorithm indicates an expected call of VerifyExpectingAlgorithm.
+func (mr *MockHasherMockRecorder) VerifyExpectingAlgorithm(pwd, hash, expectedAlg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyExpectingAlgorithm", reflect.TypeOf((*MockHasher)(nil).VerifyExpectingAlgorithm), pwd, hash, expectedAlg)
+}
+
+// VerifyWithAlgorithm mocks base method.
+func (m *MockHasher) VerifyWithAlgorithm(pwd, hash []byte) (bool, string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWithAlgorithm", pwd, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(string)
+	return ret0, ret1
+}
+
+// VerifyWithAlgorithm indicates an expected call of VerifyWithAlgorithm.
+func (mr *MockHasherMockRecorder) VerifyWithAlgorithm(pwd, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWithAlgorithm", reflect.TypeOf((*MockHasher)(nil).VerifyWithAlgorithm), pwd, hash)
+}
+
+// Verifier mocks base method.
+func (m *MockHasher) Verifier(hash []byte) (func([]byte) bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verifier", hash)
+	ret0, _ := ret[0].(func([]byte) bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verifier indicates an expected call of Verifier.
+func (mr *MockHasherMockRecorder) Verifier(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verifier", reflect.TypeOf((*MockHasher)(nil).Verifier), hash)
+}
+
+// VerifyWithPeppers mocks base method.
+func (m *MockHasher) VerifyWithPeppers(pwd, hash []byte, peppers ...[]byte) bool {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{pwd, hash}
+	for _, a := range peppers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyWithPeppers", varargs...)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyWithPeppers indicates an expected call of VerifyWithPeppers.
+func (mr *MockHasherMockRecorder) VerifyWithPeppers(pwd, hash interface{}, peppers ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{pwd, hash}, peppers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWithPeppers", reflect.TypeOf((*MockHasher)(nil).VerifyWithPeppers), varargs...)
+}
+
+// VerifyWithIdentity mocks base method.
+func (m *MockHasher) VerifyWithIdentity(pwd, hash, identity []byte) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyWithIdentity", pwd, hash, identity)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyWithIdentity indicates an expected call of VerifyWithIdentity.
+func (mr *MockHasherMockRecorder) VerifyWithIdentity(pwd, hash, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyWithIdentity", reflect.TypeOf((*MockHasher)(nil).VerifyWithIdentity), pwd, hash, identity)
+}
+
+// VerifyNotCompromised mocks base method.
+func (m *MockHasher) VerifyNotCompromised(pwd, hash []byte, blocklist hasher.BloomFilter) (bool, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyNotCompromised", pwd, hash, blocklist)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// VerifyNotCompromised indicates an expected call of VerifyNotCompromised.
+func (mr *MockHasherMockRecorder) VerifyNotCompromised(pwd, hash, blocklist interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyNotCompromised", reflect.TypeOf((*MockHasher)(nil).VerifyNotCompromised), pwd, hash, blocklist)
+}
+
+// VerifyTimingSafe mocks base method.
+func (m *MockHasher) VerifyTimingSafe(pwd, hash []byte, budget time.Duration) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTimingSafe", pwd, hash, budget)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyTimingSafe indicates an expected call of VerifyTimingSafe.
+func (mr *MockHasherMockRecorder) VerifyTimingSafe(pwd, hash, budget interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTimingSafe", reflect.TypeOf((*MockHasher)(nil).VerifyTimingSafe), pwd, hash, budget)
+}
+
+// VerifyContext mocks base method.
+func (m *MockHasher) VerifyContext(ctx context.Context, pwd, hash []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyContext", ctx, pwd, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyContext indicates an expected call of VerifyContext.
+func (mr *MockHasherMockRecorder) VerifyContext(ctx, pwd, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyContext", reflect.TypeOf((*MockHasher)(nil).VerifyContext), ctx, pwd, hash)
+}
+
+// VerifyBatch mocks base method.
+func (m *MockHasher) VerifyBatch(jobs []hasher.VerifyJob) []bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBatch", jobs)
+	ret0, _ := ret[0].([]bool)
+	return ret0
+}
+
+// VerifyBatch indicates an expected call of VerifyBatch.
+func (mr *MockHasherMockRecorder) VerifyBatch(jobs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBatch", reflect.TypeOf((*MockHasher)(nil).VerifyBatch), jobs)
+}
+
+// VerifyBatchContext mocks base method.
+func (m *MockHasher) VerifyBatchContext(ctx context.Context, jobs []hasher.VerifyJob) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBatchContext", ctx, jobs)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyBatchContext indicates an expected call of VerifyBatchContext.
+func (mr *MockHasherMockRecorder) VerifyBatchContext(ctx, jobs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBatchContext", reflect.TypeOf((*MockHasher)(nil).VerifyBatchContext), ctx, jobs)
+}
+
+// VerifyString mocks base method.
+func (m *MockHasher) VerifyString(pwd []byte, s string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyString", pwd, s)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// VerifyString indicates an expected call of VerifyString.
+func (mr *MockHasherMockRecorder) VerifyString(pwd, s interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyString", reflect.TypeOf((*MockHasher)(nil).VerifyString), pwd, s)
+}
+
+// HashSplit mocks base method.
+func (m *MockHasher) HashSplit(pwd []byte) ([]byte, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashSplit", pwd)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// HashSplit indicates an expected call of HashSplit.
+func (mr *MockHasherMockRecorder) HashSplit(pwd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashSplit", reflect.TypeOf((*MockHasher)(nil).HashSplit), pwd)
+}
+
+// VerifySplit mocks base method.
+func (m *MockHasher) VerifySplit(pwd, headerAndKey, salt []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySplit", pwd, headerAndKey, salt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySplit indicates an expected call of VerifySplit.
+func (mr *MockHasherMockRecorder) VerifySplit(pwd, headerAndKey, salt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySplit", reflect.TypeOf((*MockHasher)(nil).VerifySplit), pwd, headerAndKey, salt)
+}
+
+// VerifyPasswordReader mocks base method.
+func (m *MockHasher) VerifyPasswordReader(r io.Reader, hash []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyPasswordReader", r, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyPasswordReader indicates an expected call of VerifyPasswordReader.
+func (mr *MockHasherMockRecorder) VerifyPasswordReader(r, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPasswordReader", reflect.TypeOf((*MockHasher)(nil).VerifyPasswordReader), r, hash)
+}
+
+// VerifyRecord mocks base method.
+func (m *MockHasher) VerifyRecord(pwd []byte, r hasher.HashRecord) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRecord", pwd, r)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyRecord indicates an expected call of VerifyRecord.
+func (mr *MockHasherMockRecorder) VerifyRecord(pwd, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRecord", reflect.TypeOf((*MockHasher)(nil).VerifyRecord), pwd, r)
+}
+
+// Profile mocks base method.
+func (m *MockHasher) Profile(pwd, hash []byte) (hasher.ProfileReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Profile", pwd, hash)
+	ret0, _ := ret[0].(hasher.ProfileReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Profile indicates an expected call of Profile.
+func (mr *MockHasherMockRecorder) Profile(pwd, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Profile", reflect.TypeOf((*MockHasher)(nil).Profile), pwd, hash)
+}
+
+// Algorithm mocks base method.
+func (m *MockHasher) Algorithm() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Algorithm")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Algorithm indicates an expected call of Algorithm.
+func (mr *MockHasherMockRecorder) Algorithm() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Algorithm", reflect.TypeOf((*MockHasher)(nil).Algorithm))
+}
+
+// Params mocks base method.
+func (m *MockHasher) Params() hasher.Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Params")
+	ret0, _ := ret[0].(hasher.Config)
+	return ret0
+}
+
+// Params indicates an expected call of Params.
+func (mr *MockHasherMockRecorder) Params() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Params", reflect.TypeOf((*MockHasher)(nil).Params))
+}
+
+// Compatible mocks base method.
+func (m *MockHasher) Compatible(other hasher.Hasher) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compatible", other)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Compatible indicates an expected call of Compatible.
+func (mr *MockHasherMockRecorder) Compatible(other interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compatible", reflect.TypeOf((*MockHasher)(nil).Compatible), other)
+}
+
+// NeedsRehash mocks base method.
+func (m *MockHasher) NeedsRehash(hash []byte) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockHasherMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockHasher)(nil).NeedsRehash), hash)
+}
+
+// DeriveKeys mocks base method.
+func (m *MockHasher) DeriveKeys(pwd, salt []byte, sizes ...int) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{pwd, salt}
+	for _, a := range sizes {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeriveKeys", varargs...)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeriveKeys indicates an expected call of DeriveKeys.
+func (mr *MockHasherMockRecorder) DeriveKeys(pwd, salt interface{}, sizes ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{pwd, salt}, sizes...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeriveKeys", reflect.TypeOf((*MockHasher)(nil).DeriveKeys), varargs...)
+}
+
+// VerifyAndDeriveKey mocks base method.
+func (m *MockHasher) VerifyAndDeriveKey(pwd, hash []byte, extraKeyLen int) (bool, []byte) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAndDeriveKey", pwd, hash, extraKeyLen)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].([]byte)
+	return ret0, ret1
+}
+
+// VerifyAndDeriveKey indicates an expected call of VerifyAndDeriveKey.
+func (mr *MockHasherMockRecorder) VerifyAndDeriveKey(pwd, hash, extraKeyLen interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAndDeriveKey", reflect.TypeOf((*MockHasher)(nil).VerifyAndDeriveKey), pwd, hash, extraKeyLen)
+}
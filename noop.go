@@ -0,0 +1,296 @@
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// noopMarker is the prefix of hashes produced by NoopHasher. The leading zero
+	// byte is not a valid format marker, so they are never read as real hashes.
+	noopMarker = "\x00noop-insecure:"
+
+	// noopStringPrefix is the prefix of strings produced by NoopHasher.HashString.
+	noopStringPrefix = "$noop-insecure$"
+)
+
+// NoopHasher is a Hasher which stores passwords in plaintext, for environments
+// where real hashing should be disabled without changing call sites, such as to
+// produce deterministic development fixtures.
+//
+// WARNING: NoopHasher provides no security at all. Its hashes contain the password
+// itself, so it must never be used in production. Hashes are prefixed with a distinct
+// marker, so they can't be mistaken for, or verified as, real hashes by New's hashers.
+type NoopHasher struct{}
+
+// Hash returns the password, prefixed with the noop marker.
+func (NoopHasher) Hash(pwd []byte) ([]byte, error) {
+	return append([]byte(noopMarker), pwd...), nil
+}
+
+// HashAppend appends the password, prefixed with the noop marker, to dst.
+func (NoopHasher) HashAppend(dst, pwd []byte) ([]byte, error) {
+	return append(append(dst, noopMarker...), pwd...), nil
+}
+
+// HashWithIdentity returns the password, prefixed with the noop marker and the SHA-256
+// digest of the identity, so it only matches VerifyWithIdentity with the same identity.
+func (n NoopHasher) HashWithIdentity(pwd, identity []byte) ([]byte, error) {
+	if len(identity) == 0 {
+		return nil, ErrInvalidIdentity
+	}
+
+	return n.Hash(noopIdentity(pwd, identity))
+}
+
+// HashWithProgress returns the password, prefixed with the noop marker,
+// reporting a single, complete, iteration to the callback, if non-nil.
+func (n NoopHasher) HashWithProgress(pwd []byte, progress func(done, total int)) ([]byte, error) {
+	if progress != nil {
+		progress(1, 1)
+	}
+
+	return n.Hash(pwd)
+}
+
+// HashContext returns the password, prefixed with the noop marker.
+func (n NoopHasher) HashContext(ctx context.Context, pwd []byte) ([]byte, error) {
+	return n.Hash(pwd)
+}
+
+// HashString returns the password, as a string in the format "$noop-insecure$<password>",
+// where the password is encoded using unpadded, standard base64.
+func (NoopHasher) HashString(pwd []byte) (string, error) {
+	return noopStringPrefix + base64.RawStdEncoding.EncodeToString(pwd), nil
+}
+
+// Verify returns a flag which determines whether or not the hash was produced by
+// NoopHasher, with the given password.
+func (n NoopHasher) Verify(pwd, hash []byte) bool {
+	return n.VerifyWithReason(pwd, hash) == nil
+}
+
+// VerifyWithReason verifies the password against the hash, returning ErrInvalidFormat
+// if it was not produced by NoopHasher, or ErrPasswordMismatch if it doesn't match.
+func (NoopHasher) VerifyWithReason(pwd, hash []byte) error {
+	if !bytes.HasPrefix(hash, []byte(noopMarker)) {
+		return ErrInvalidFormat
+	}
+
+	if !ConstantTimeEqual(hash[len(noopMarker):], pwd) {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+// VerifyExpectingAlgorithm verifies the password against the hash, in the same way
+// as Verify, but returns false if expectedAlg is not 0, as no algorithm is used.
+func (n NoopHasher) VerifyExpectingAlgorithm(pwd, hash []byte, expectedAlg int) bool {
+	return expectedAlg == 0 && n.Verify(pwd, hash)
+}
+
+// VerifyWithAlgorithm verifies the password against the hash, in the same way as
+// Verify, returning an empty algorithm name, as no algorithm is used.
+func (n NoopHasher) VerifyWithAlgorithm(pwd, hash []byte) (ok bool, algName string) {
+	return n.Verify(pwd, hash), ""
+}
+
+// Verifier returns a function which verifies a password against the hash, in the same
+// way as Verify, or ErrInvalidFormat if the hash was not produced by NoopHasher.
+func (n NoopHasher) Verifier(hash []byte) (func(pwd []byte) bool, error) {
+	if !bytes.HasPrefix(hash, []byte(noopMarker)) {
+		return nil, ErrInvalidFormat
+	}
+
+	hash = append([]byte{}, hash...)
+
+	return func(pwd []byte) bool {
+		return n.Verify(pwd, hash)
+	}, nil
+}
+
+// VerifyWithIdentity verifies the password against a hash produced by HashWithIdentity,
+// with the same identity.
+func (n NoopHasher) VerifyWithIdentity(pwd, hash, identity []byte) bool {
+	return len(identity) > 0 && n.Verify(noopIdentity(pwd, identity), hash)
+}
+
+// VerifyWithPeppers verifies the password, peppered with each of the given peppers,
+// against the hash, in the same way as Hasher.VerifyWithPeppers.
+func (n NoopHasher) VerifyWithPeppers(pwd, hash []byte, peppers ...[]byte) bool {
+	return verifyWithPeppers(n, pwd, hash, peppers)
+}
+
+// VerifyNotCompromised verifies the password against the hash, in the same way as
+// Verify, and checks whether it might appear in the blocklist, see Hasher.
+func (n NoopHasher) VerifyNotCompromised(pwd, hash []byte, blocklist BloomFilter) (verified, compromised bool) {
+	return verifyNotCompromised(n, pwd, hash, blocklist)
+}
+
+// VerifyTimingSafe verifies the password against the hash, in the same way as
+// Verify, padding the time taken to the budget, see Hasher.
+func (n NoopHasher) VerifyTimingSafe(pwd, hash []byte, budget time.Duration) bool {
+	return verifyTimingSafe(n, pwd, hash, budget)
+}
+
+// HashPasswordReader reads a password from r, of at most DefaultMaxPasswordLength
+// bytes, then "hashes" it in the same way as Hash, see Hasher.
+func (n NoopHasher) HashPasswordReader(r io.Reader) ([]byte, error) {
+	pwd, err := readPassword(r, DefaultMaxPasswordLength)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(pwd)
+
+	return n.Hash(pwd)
+}
+
+// VerifyPasswordReader reads a password from r, of at most DefaultMaxPasswordLength
+// bytes, then verifies it against the hash in the same way as Verify, see Hasher.
+func (n NoopHasher) VerifyPasswordReader(r io.Reader, hash []byte) (bool, error) {
+	pwd, err := readPassword(r, DefaultMaxPasswordLength)
+	if err != nil {
+		return false, err
+	}
+	defer wipe(pwd)
+
+	return n.Verify(pwd, hash), nil
+}
+
+// VerifyContext verifies the password against the hash, in the same way as Verify.
+func (n NoopHasher) VerifyContext(ctx context.Context, pwd, hash []byte) (bool, error) {
+	return n.Verify(pwd, hash), nil
+}
+
+// VerifyBatch verifies each job's password against its hash, in the same way as
+// Verify, see Hasher.
+func (n NoopHasher) VerifyBatch(jobs []VerifyJob) []bool {
+	results, _ := n.VerifyBatchContext(context.Background(), jobs)
+	return results
+}
+
+// VerifyBatchContext verifies the jobs in the same way as VerifyBatch, stopping
+// once the context is done, see Hasher.
+func (n NoopHasher) VerifyBatchContext(ctx context.Context, jobs []VerifyJob) ([]bool, error) {
+	return verifyBatch(ctx, n, jobs)
+}
+
+// VerifyRecord returns ErrInvalidFormat, as hashes produced by NoopHasher can't be
+// converted to records.
+func (NoopHasher) VerifyRecord(pwd []byte, r HashRecord) (bool, error) {
+	return false, ErrInvalidFormat
+}
+
+// VerifyString verifies the password against a string produced by HashString.
+func (n NoopHasher) VerifyString(pwd []byte, s string) bool {
+	if !strings.HasPrefix(s, noopStringPrefix) {
+		return false
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(s, noopStringPrefix))
+	if err != nil {
+		return false
+	}
+
+	return ConstantTimeEqual(expected, pwd)
+}
+
+// Profile verifies the password against the hash, in the same way as VerifyWithReason,
+// returning a report with only the total time spent, as nothing is derived.
+func (n NoopHasher) Profile(pwd, hash []byte) (ProfileReport, error) {
+	start := time.Now()
+	err := n.VerifyWithReason(pwd, hash)
+
+	return ProfileReport{Total: time.Since(start)}, err
+}
+
+// HashSplit returns the password, prefixed with the noop marker, and an empty salt,
+// as no salt is used.
+func (n NoopHasher) HashSplit(pwd []byte) (headerAndKey, salt []byte, err error) {
+	hash, err := n.Hash(pwd)
+	return hash, []byte{}, err
+}
+
+// VerifySplit verifies the password against a hash produced by HashSplit, returning
+// ErrInvalidSplit if the salt is not empty.
+func (n NoopHasher) VerifySplit(pwd, headerAndKey, salt []byte) (bool, error) {
+	if len(salt) != 0 {
+		return false, ErrInvalidSplit
+	}
+
+	return n.Verify(pwd, headerAndKey), nil
+}
+
+// Algorithm returns 0, as no algorithm is used.
+func (NoopHasher) Algorithm() int {
+	return 0
+}
+
+// Compatible returns true if the other hasher is also a NoopHasher.
+func (NoopHasher) Compatible(other Hasher) bool {
+	_, ok := other.(NoopHasher)
+	return ok
+}
+
+// Params returns a zero Config, as no parameters are used.
+func (NoopHasher) Params() Config {
+	return Config{}
+}
+
+// VerifyAndDeriveKey verifies the password against the hash, in the same way as Verify,
+// and if the password matches, expands a session key of extraKeyLen bytes directly from
+// the password, using HKDF-SHA256, in the same way as Hasher.VerifyAndDeriveKey, but
+// without pbkdf2.
+func (n NoopHasher) VerifyAndDeriveKey(pwd, hash []byte, extraKeyLen int) (ok bool, sessionKey []byte) {
+	if extraKeyLen < 1 || extraKeyLen > 255*sha256.Size || !n.Verify(pwd, hash) {
+		return false, nil
+	}
+
+	sessionKey = make([]byte, extraKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, pwd, nil, []byte(sessionKeyInfo)), sessionKey); err != nil {
+		return false, nil
+	}
+
+	return true, sessionKey
+}
+
+// NeedsRehash returns true if the hash was not produced by NoopHasher.
+func (NoopHasher) NeedsRehash(hash []byte) bool {
+	return !bytes.HasPrefix(hash, []byte(noopMarker))
+}
+
+// DeriveKeys expands keys of the given sizes directly from the password and salt,
+// using HKDF-SHA256, in the same way as Hasher.DeriveKeys, but without pbkdf2. Each
+// size must be positive, and at most 255 times the SHA256 digest size.
+func (NoopHasher) DeriveKeys(pwd, salt []byte, sizes ...int) ([][]byte, error) {
+	for _, size := range sizes {
+		if size < 1 {
+			return nil, ErrInvalidKeyLength
+		}
+
+		if size > 255*sha256.Size {
+			return nil, ErrExpandTooLarge
+		}
+	}
+
+	keys := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		info := []byte(deriveKeysInfo + strconv.Itoa(i))
+		keys[i] = make([]byte, size)
+
+		if _, err := io.ReadFull(hkdf.New(sha256.New, pwd, salt, info), keys[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,71 @@
+package hasher
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNoopHasher(t *testing.T) {
+	var hasher Hasher = NoopHasher{}
+	pwd := []byte("MyTestPassword")
+
+	hash, err := hasher.Hash(pwd)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if !hasher.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	if hasher.Verify([]byte("NotMyPassword"), hash) {
+		t.Errorf("expected hash to be invalid")
+	}
+
+	if hasher.NeedsRehash(hash) {
+		t.Errorf("didn't expect hash to need rehashing")
+	}
+
+	t.Run("Distinct From Real Hashes", func(t *testing.T) {
+		if Verify(pwd, hash) {
+			t.Errorf("expected a noop hash to be invalid for a real hasher")
+		}
+
+		if _, err := Inspect(hash); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+
+		realHash := mustHash(t, pwd)
+		if hasher.Verify(pwd, realHash) {
+			t.Errorf("expected a real hash to be invalid for a noop hasher")
+		}
+
+		if !hasher.NeedsRehash(realHash) {
+			t.Errorf("expected a real hash to need rehashing")
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		s, _ := hasher.HashString(pwd)
+		if !hasher.VerifyString(pwd, s) {
+			t.Errorf("expected string to be valid")
+		}
+
+		if hasher.VerifyString([]byte("NotMyPassword"), s) {
+			t.Errorf("expected string to be invalid")
+		}
+
+		if defaultHasher.VerifyString(pwd, s) {
+			t.Errorf("expected a noop string to be invalid for a real hasher")
+		}
+	})
+
+	t.Run("Derive Keys", func(t *testing.T) {
+		a, _ := hasher.DeriveKeys(pwd, []byte("salt"), 32, 32)
+		b, _ := hasher.DeriveKeys(pwd, []byte("salt"), 32, 32)
+		if !bytes.Equal(a[0], b[0]) || bytes.Equal(a[0], a[1]) {
+			t.Errorf("expected keys to be deterministic and independent")
+		}
+	})
+}
@@ -0,0 +1,6 @@
+//go:build !race
+
+package hasher
+
+// raceEnabled reports whether the race detector is enabled, see race_test.go.
+const raceEnabled = false
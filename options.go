@@ -0,0 +1,415 @@
+package hasher
+
+import "time"
+
+// Option is used to configure optional behaviour of a Hasher, and
+// can be passed to New.
+type Option func(h *hasher)
+
+// WithKeyTruncation configures the hasher to only store the first n bytes
+// of the derived sub-key, which can be used to fit hashes into fixed-width
+// storage. The full sub-key is still derived, but only the truncated prefix
+// is written to the output, and compared when verifying.
+//
+// Truncating the sub-key reduces the security of the hash, as fewer bytes
+// of the derived key need to be matched by an attacker. This should only be
+// used where storage constraints leave no other choice.
+//
+// n must be positive and no greater than the hasher's key size, in bytes.
+func WithKeyTruncation(n int) Option {
+	return func(h *hasher) {
+		h.truncLen = n
+		h.truncate = true
+	}
+}
+
+// WithSaltTracker configures the hasher to record every salt it generates
+// with the given SaltTracker. If a duplicate salt is detected, it is logged.
+//
+// This is intended for test and canary environments, see SaltTracker.
+func WithSaltTracker(t *SaltTracker) Option {
+	return func(h *hasher) {
+		h.tracker = t
+	}
+}
+
+// WithPreHash configures the hasher to hash passwords using the algorithm
+// for the given hash key, before they're passed to pbkdf2. This normalizes
+// the length of the input, so the cost of hashing long passwords is the same
+// as short ones.
+//
+// A flag is recorded in the header of each hash, so verification applies the same
+// pre-hash. The given key must be a supported hash key, such as HashSHA512.
+func WithPreHash(hashKey int) Option {
+	return func(h *hasher) {
+		h.preHash = hashKey
+	}
+}
+
+// WithOuterHash configures the hasher to pass each derived sub-key through an outer
+// HMAC, using the algorithm for the given hash key, such as HashSHA512, so a hash
+// stays secure if either the pbkdf2 algorithm, or the outer algorithm, is broken. The
+// outer HMAC's output is truncated to the sub-key's size, so the size is unchanged.
+//
+// The outer hash's key is recorded in the header of each hash, so verification applies
+// the same outer hash, and hashes without one can still be verified. The given key must
+// be a supported hash key, whose digest is at least as long as the (truncated) key size.
+func WithOuterHash(hashKey int) Option {
+	return func(h *hasher) {
+		h.outer = hashKey
+	}
+}
+
+// WithRecommendedAlgorithm configures the hasher to use the algorithm returned
+// by RecommendAlgorithm, in place of the hash key given to New.
+func WithRecommendedAlgorithm() Option {
+	return func(h *hasher) {
+		h.hashKey = RecommendAlgorithm()
+	}
+}
+
+// WithAllowedAlgorithms configures the hasher to only verify hashes produced with
+// one of the given hash keys. Verify returns false, and VerifyWithReason returns
+// ErrAlgorithmNotAllowed, for hashes using any other algorithm, even if it's still
+// supported, so logins backed by a retired algorithm can be forced to reset.
+//
+// By default, all supported algorithms are allowed. Each key must be supported, and
+// the hasher's own algorithm must be included, so its hashes can still be verified.
+func WithAllowedAlgorithms(hashKeys ...int) Option {
+	return func(h *hasher) {
+		h.allowed = make(map[int]bool, len(hashKeys))
+		for _, key := range hashKeys {
+			h.allowed[key] = true
+		}
+	}
+}
+
+// WithAlgorithmSunset configures the hasher to stop verifying hashes produced with the
+// given hash key once the time is after the given sunset, so a weak algorithm can be
+// retired across a fleet, on a schedule, without a deploy at the cutoff. Until then, its
+// hashes are verified as usual, and reported by NeedsRehash, so they can be replaced.
+// After it, Verify returns false, and VerifyWithReason returns ErrAlgorithmSunset, so
+// any passwords not rehashed in time must be reset.
+//
+// The sunset is compared with the hasher's own clock, so hosts whose clocks are skewed
+// will retire the algorithm up to the skew apart, and a login may be accepted by one
+// host moments after being rejected by another. Keep clocks synchronized, and don't
+// rely on the cutoff being exact to the second.
+//
+// The option can be given once for each algorithm to retire. Each key must be supported,
+// and must not be the hasher's own algorithm, otherwise New returns
+// ErrInvalidAlgorithmSunset.
+func WithAlgorithmSunset(hashKey int, after time.Time) Option {
+	return func(h *hasher) {
+		if h.sunsets == nil {
+			h.sunsets = make(map[int]time.Time)
+		}
+
+		h.sunsets[hashKey] = after
+	}
+}
+
+// WithContext configures the hasher to derive keys with the given context, or purpose,
+// such as "login" or "recovery", for domain separation between different uses of the same
+// password. The context is appended to the salt given to pbkdf2, similar to HKDF's info
+// parameter, so hashes produced with different contexts won't verify against each other.
+//
+// A flag is recorded in the header of each hash, but the context itself is not stored,
+// so Verify requires a hasher with the same context, and rejects hashes with a context
+// if the hasher has none, and vice versa. The context must not be empty.
+func WithContext(c []byte) Option {
+	return func(h *hasher) {
+		h.context = append([]byte{}, c...)
+	}
+}
+
+// WithFIPSMode configures whether or not the hasher is restricted to algorithms and
+// parameters approved for FIPS 140 deployments, per NIST SP 800-132. When enabled, New
+// returns ErrNotFIPSApproved, and Verify rejects hashes, unless:
+//   - the algorithm, and pre-hash algorithm, if any, is SHA256 or SHA512,
+//   - the iteration count is at least 1000,
+//   - the salt size is at least 128 bits,
+//   - and the stored key size is at least 112 bits, after any truncation.
+func WithFIPSMode(enabled bool) Option {
+	return func(h *hasher) {
+		h.fips = enabled
+	}
+}
+
+// WithSaltPosition configures the order in which Verify reads the salt and sub-key
+// following the header of a hash, so hashes from legacy formats which store the sub-key
+// before the salt can be verified, using SaltAfterKey. New hashes are always written
+// with the salt first, regardless of the position, so a hasher using SaltAfterKey
+// should only be used to verify legacy hashes.
+//
+// Hashes read with SaltAfterKey must declare the sub-key's length in their header, as
+// with WithKeyLengthInHeader, otherwise they're rejected as corrupt.
+func WithSaltPosition(pos SaltPosition) Option {
+	return func(h *hasher) {
+		h.saltPos = pos
+	}
+}
+
+// WithRejectNullBytes configures whether or not the hasher rejects passwords containing
+// a null byte, returning ErrNullByte from Hash. By default, null bytes are preserved, and
+// hashed like any other byte, but some systems truncate passwords at the first null byte,
+// so a hash produced by one would never verify with the other. Rejecting them catches
+// these interop bugs early. Verification is unaffected.
+func WithRejectNullBytes(enabled bool) Option {
+	return func(h *hasher) {
+		h.noNulls = enabled
+	}
+}
+
+// WithMaxPasswordLength configures the maximum length, in bytes, of passwords read
+// by HashPasswordReader and VerifyPasswordReader, which return ErrPasswordTooLong for
+// longer passwords, so reads are never unbounded. Passwords given directly to Hash and
+// Verify are not limited. Defaults to DefaultMaxPasswordLength, and must be positive.
+func WithMaxPasswordLength(n int) Option {
+	return func(h *hasher) {
+		h.maxPwdLen = n
+	}
+}
+
+// WithMinIterationRatio configures Verify to reject hashes whose iteration count is less
+// than r times the hasher's, for example, 0.5 rejects hashes with less than half of the
+// current iteration count, while VerifyWithReason returns ErrHashTooWeak. Unlike a fixed
+// minimum, the threshold follows the hasher's iteration count as it's raised over time.
+//
+// NeedsRehash already reports any hash with fewer iterations than the hasher's, so they
+// can be replaced the next time the password is verified. Hashes below the ratio can't
+// be verified, and so can't be rehashed, forcing a password reset instead. Raising the
+// ratio gradually lets cheap hashes be phased out once most have been rehashed.
+//
+// r must be between 0 and 1, and a zero r disables the check, which is the default.
+func WithMinIterationRatio(r float64) Option {
+	return func(h *hasher) {
+		h.minRatio = r
+	}
+}
+
+// WithParameterWindow configures the hasher to reject hashes, when verifying, whose
+// parameters fall outside the window [min, max], in any dimension, as a complete policy
+// for which hashes are accepted. Hashes below the window are too weak to trust, and hashes
+// above it are suspiciously strong, such as a hash crafted with a huge iteration count,
+// which would tie up the server verifying it, so both force a password reset.
+//
+// Each bound is inclusive, and is interpreted as follows:
+//
+//   - IterationCount bounds the hash's iteration count.
+//   - SaltSize and KeySize bound the sizes of the hash's salt and stored sub-key, in bits,
+//     so a truncated sub-key, see WithKeyTruncation, is bounded by its truncated size.
+//   - HashKey must be zero in both, as algorithms aren't ordered, see WithAllowedAlgorithms.
+//
+// A zero field leaves the window unbounded in that direction, so, for example, a zero max
+// only sets minimums. A hash outside the window fails with a *WindowError, wrapping either
+// ErrHashTooWeak or ErrHashTooStrong, which is returned by VerifyWithReason before the
+// sub-key is derived, so the bounds aren't secret from anyone timing crafted hashes. A
+// hash below the hasher's own salt or key size still fails as described by Verify.
+//
+// Invalid bounds, or a window which excludes the hasher's own parameters, cause New to
+// return ErrInvalidParameterWindow. With WithAdaptiveCost, the maximum iteration count
+// should allow for the count being adjusted upwards.
+func WithParameterWindow(min, max Config) Option {
+	return func(h *hasher) {
+		h.winMin, h.winMax = &min, &max
+	}
+}
+
+// WithVerifyCache configures the hasher to cache the results of Verify, for
+// up to size recent password and hash pairs, for the given ttl. This avoids
+// repeating an expensive derivation when the same password is verified against
+// the same hash in quick succession, for example, when a user retries a login.
+//
+// Caching verification results is a trade-off between performance and security:
+//   - a cached result is returned without the cost of a derivation, so within
+//     the ttl, repeated guesses of the same password are cheap,
+//   - results stay valid for the ttl, even if the stored hash is changed elsewhere,
+//   - cache keys are a HMAC of the password and hash, using a random key held
+//     only in memory, so plaintext passwords are never stored.
+//
+// Keep the ttl short. Both size and ttl must be positive.
+func WithVerifyCache(size int, ttl time.Duration) Option {
+	return func(h *hasher) {
+		h.cacheSize = size
+		h.cacheTTL = ttl
+	}
+}
+
+// WithConcurrencyLimit configures the hasher to perform at most n hash or verify
+// operations at once, capping the CPU used for hashing, regardless of the number
+// of callers, for example, under a credential-stuffing attack on a login endpoint.
+//
+// Calls exceeding the limit block until a slot is free, rather than being dropped.
+// HashContext and VerifyContext stop waiting, returning an error, once their context
+// is done. The limit is shared by all calls to the hasher, and must be positive.
+func WithConcurrencyLimit(n int) Option {
+	return func(h *hasher) {
+		h.concurrency = n
+		h.limited = true
+	}
+}
+
+// WithTimeout configures the hasher to stop each derivation which takes longer than d,
+// so a hash with a pathological iteration count, whether malicious or a mistake, can't
+// leave a call hanging, without every caller passing a context. Hash returns, and
+// VerifyContext and VerifyWithReason return, an *InterruptedError, while Verify returns
+// false. The timeout is applied to the derivation on its own, not the time spent waiting
+// for a slot, see WithConcurrencyLimit.
+//
+// The deadline is checked by the iteration loop every 1000 iterations, rather than
+// interrupting it, so a call may overrun it slightly. A zero d disables the timeout,
+// which is the default, and a negative d is invalid.
+func WithTimeout(d time.Duration) Option {
+	return func(h *hasher) {
+		h.timeout = d
+	}
+}
+
+// WithAdaptiveCost configures the hasher to adjust the iteration count used by Hash, so
+// each derivation takes close to the target latency, as the hardware, or its load,
+// changes over the life of a long-running service. The latency of every derivation is
+// recorded, and after each adjustEvery derivations, the iteration count is scaled by the
+// ratio of the target to their average latency.
+//
+// Adjustments are bounded, so the cost can't run away, or collapse under load:
+//   - each adjustment changes the iteration count by at most 25%,
+//   - it never falls below the iteration count given to New, which remains the minimum
+//     used by NeedsRehash, and is reported by Params,
+//   - and it never exceeds 16 times the iteration count given to New.
+//
+// Verification always uses the iteration count stored in each hash's header, so hashes
+// stay valid as the count changes. Both targetLatency and adjustEvery must be positive.
+func WithAdaptiveCost(targetLatency time.Duration, adjustEvery int) Option {
+	return func(h *hasher) {
+		h.adaptTarget = targetLatency
+		h.adaptEvery = adjustEvery
+	}
+}
+
+// WithMinHashDuration configures the hasher to pad each call to Hash, which returns in
+// less than d, with a sleep, so every call takes at least d, even if the iteration count
+// is too low for the hardware. This makes the wall-clock cost of each attempt against an
+// endpoint which hashes passwords, such as sign-up or a password change, predictable.
+//
+// Padding does NOT increase the cryptographic strength of hashes, as an attacker with
+// the hashes computes them without the sleep, so it's no substitute for raising the
+// iteration count. The sleep happens once any concurrency slot is released, see
+// WithConcurrencyLimit, and HashContext stops sleeping once its context is done.
+//
+// A zero d disables the padding, which is the default, and a negative d is invalid.
+func WithMinHashDuration(d time.Duration) Option {
+	return func(h *hasher) {
+		h.minTime = d
+	}
+}
+
+// WithSaltSource configures the hasher to generate salts using the given
+// SaltSource, rather than crypto/rand. Any error returned by the source is
+// returned from Hash. For golden tests, see SequenceSalts.
+func WithSaltSource(s SaltSource) Option {
+	return func(h *hasher) {
+		h.saltSource = s
+	}
+}
+
+// WithMinSaltEntropy configures the hasher to check the entropy of each salt it generates,
+// as a canary for a broken random number generator, such as one which is stuck, or
+// repeating a short pattern. A salt whose entropy is less than bitsPerByte is discarded,
+// and regenerated, and if 3 salts in a row fall short, Hash returns ErrLowSaltEntropy.
+//
+// The entropy is the Shannon entropy of the salt's byte values, estimated from how often
+// each occurs. The estimate can't exceed 8 bits per byte, or log2 of the salt size, in
+// bytes, and a random salt often falls short of it, as some byte values repeat, so the
+// minimum must leave a margin: it's at most 1 less than log2 of the salt size, and at
+// most 6, otherwise New returns ErrInvalidSaltEntropy. For a 128-bit salt, that's 3,
+// which catches a broken generator, while random 128-bit salts almost never fall below
+// it. This is a heuristic, not a guarantee, as an RNG can be predictable and still look
+// random.
+func WithMinSaltEntropy(bitsPerByte float64) Option {
+	return func(h *hasher) {
+		h.minEntropy = bitsPerByte
+	}
+}
+
+// WithDeterministicSalt configures the hasher to generate salts from a ChaCha20
+// keystream, keyed by the given seed, rather than crypto/rand. Hashers with the same
+// seed produce the same sequence of salts, which are still well-distributed, so load
+// tests and benchmarks can be reproduced, with distinct salts for each virtual user.
+//
+// This is insecure, and must only be used for testing and benchmarking. Anyone who
+// knows the seed can predict every salt, so never use it in production.
+func WithDeterministicSalt(seed []byte) Option {
+	return func(h *hasher) {
+		h.saltSource = newSeededSource(seed)
+	}
+}
+
+// WithTimestamp configures whether or not the hasher records the time each hash
+// was created, in its header. The creation time can be read using Inspect, and
+// used by NeedsRehash, to enforce a max age set with WithMaxAge.
+//
+// Version 1 hashes never contain a creation time.
+func WithTimestamp(enabled bool) Option {
+	return func(h *hasher) {
+		h.timestamp = enabled
+	}
+}
+
+// WithMaxAge configures NeedsRehash to report hashes which were created more
+// than d ago, even if their parameters are still acceptable. This can be used
+// to rehash passwords periodically. Hashes with no recorded creation time are
+// treated as too old, so should be used alongside WithTimestamp.
+//
+// A zero d disables the max age, and a negative d is invalid.
+func WithMaxAge(d time.Duration) Option {
+	return func(h *hasher) {
+		h.maxAge = d
+	}
+}
+
+// WithOutputFormatVersion configures the format version of the hashes produced by Hash,
+// so hashes can still be produced in version 1 while services which only read version 1
+// are upgraded, decoupling upgrades to reading and writing hashes across a fleet. Verify
+// reads hashes in every supported version, regardless. NeedsRehash reports hashes in any
+// other version, so set the version back to HeaderVersion once the upgrade is complete.
+// Use CompactHeaderVersion to produce smaller hashes, for storage-sensitive deployments,
+// once every reader supports it.
+//
+// Version 1 hashes have no flags, so can't be used with WithPreHash, WithTimestamp,
+// WithKeyLengthInHeader, WithContext or WithOuterHash, and HashWithIdentity returns
+// ErrInvalidFormatVersion. All algorithms are supported by every version. Defaults to
+// HeaderVersion.
+func WithOutputFormatVersion(v int) Option {
+	return func(h *hasher) {
+		h.version = v
+	}
+}
+
+// WithIgnoreUnknownFormatVersion configures whether or not Verify makes a best-effort
+// attempt to read hashes in a format version newer than any supported, produced by newer
+// releases, by reading them as if they were in the layout of HeaderVersion. Without it,
+// which is the default, they are rejected with ErrUnsupportedVersion, so a service which
+// hasn't been upgraded fails loudly, rather than misreading a hash it doesn't understand.
+//
+// Only enable it if the newer version is known to keep the current layout, such as
+// during a rolling upgrade where it does. Hashes whose headers have flags unknown to this
+// version are still rejected, but a layout which differs otherwise may be misread.
+func WithIgnoreUnknownFormatVersion(enabled bool) Option {
+	return func(h *hasher) {
+		h.ignoreVersion = enabled
+	}
+}
+
+// WithKeyLengthInHeader configures whether or not the hasher records the length
+// of the sub-key in the header of each hash. When the length is recorded, Verify
+// ignores any bytes following the sub-key, making hashes robust to storage layers
+// which pad values, or concatenate them with other data.
+//
+// Without it, as with all version 1 hashes, the sub-key is read as the remainder
+// of the hash, following the salt.
+func WithKeyLengthInHeader(enabled bool) Option {
+	return func(h *hasher) {
+		h.keyLenHdr = enabled
+	}
+}
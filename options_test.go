@@ -0,0 +1,1070 @@
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestWithKeyTruncation(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	hasher, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithKeyTruncation(16))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, _ := hasher.Hash(pwd)
+
+	t.Run("Length", func(t *testing.T) {
+		expected := headerSizeV2 + DefaultSaltSize/8 + 16
+		if len(hash) != expected {
+			t.Errorf("expected a hash length of %d, but got %d", expected, len(hash))
+		}
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		hdr, _ := scanHeader(hash)
+		salt := hash[hdr.size : hdr.size+hdr.saltLen]
+		full := pbkdf2.Key(pwd, salt, hdr.iterCnt, DefaultKeySize/8, alg(DefaultHashKey))
+
+		if string(hash[hdr.size+hdr.saltLen:]) != string(full[:16]) {
+			t.Errorf("expected the stored sub-key to be a prefix of the full sub-key")
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !hasher.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if hasher.Verify([]byte("NotMyPassword"), hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Untruncated Hasher", func(t *testing.T) {
+		// the default hasher requires the full sub-key.
+		if Verify(pwd, hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Invalid Truncation", func(t *testing.T) {
+		for _, n := range []int{-1, 0, DefaultKeySize/8 + 1} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithKeyTruncation(n))
+			if err != ErrInvalidKeyTruncation {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidKeyTruncation, err)
+			}
+		}
+	})
+}
+
+func TestWithPreHash(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	hasher, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithPreHash(HashSHA512))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, _ := hasher.Hash(pwd)
+
+	t.Run("Header", func(t *testing.T) {
+		hdr, err := scanHeader(hash)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			return
+		}
+
+		if hdr.flags&flagPreHash == 0 {
+			t.Errorf("expected the pre-hash flag to be set")
+		}
+
+		if hdr.preHash != HashSHA512 {
+			t.Errorf("expected a pre-hash key of %d, but got %d", HashSHA512, hdr.preHash)
+		}
+
+		if hdr.size != headerSizeV2+4 {
+			t.Errorf("expected a header size of %d, but got %d", headerSizeV2+4, hdr.size)
+		}
+	})
+
+	t.Run("Pre-Hashed", func(t *testing.T) {
+		hdr, _ := scanHeader(hash)
+		salt := hash[hdr.size : hdr.size+hdr.saltLen]
+		subKey := pbkdf2.Key(preHash(pwd, HashSHA512), salt, hdr.iterCnt, DefaultKeySize/8, alg(DefaultHashKey))
+
+		if string(hash[hdr.size+hdr.saltLen:]) != string(subKey) {
+			t.Errorf("expected the sub-key to be derived from the pre-hashed password")
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !hasher.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if hasher.Verify([]byte("NotMyPassword"), hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		// the default hasher reads the flag from the header.
+		if !Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		// hashes without the flag are verified without a pre-hash.
+		if !hasher.Verify(pwd, mustHash(t, pwd)) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Unsupported Hash Key", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithPreHash(237))
+		if err != ErrUnsupportedHashKey {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedHashKey, err)
+		}
+	})
+}
+
+func TestWithOuterHash(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithOuterHash(HashSHA512), WithKeyLengthInHeader(true))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, _ := h.Hash(pwd)
+
+	t.Run("Header", func(t *testing.T) {
+		hdr, err := scanHeader(hash)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			return
+		}
+
+		if hdr.flags&flagOuterHash == 0 {
+			t.Errorf("expected the outer hash flag to be set")
+		}
+
+		if hdr.outerHash != HashSHA512 {
+			t.Errorf("expected an outer hash key of %d, but got %d", HashSHA512, hdr.outerHash)
+		}
+
+		// the outer hash key follows the key length.
+		if hdr.keyLen != DefaultKeySize/8 {
+			t.Errorf("expected a key length of %d, but got %d", DefaultKeySize/8, hdr.keyLen)
+		}
+
+		if hdr.size != headerSizeV2+8 {
+			t.Errorf("expected a header size of %d, but got %d", headerSizeV2+8, hdr.size)
+		}
+
+		if info, _ := Inspect(hash); info.OuterHash != HashSHA512 {
+			t.Errorf("expected Inspect to report an outer hash key of %d, but got %d", HashSHA512, info.OuterHash)
+		}
+	})
+
+	t.Run("Outer Hashed", func(t *testing.T) {
+		hdr, _ := scanHeader(hash)
+		salt := hash[hdr.size : hdr.size+hdr.saltLen]
+		inner := pbkdf2.Key(pwd, salt, hdr.iterCnt, DefaultKeySize/8, alg(DefaultHashKey))
+
+		mac := hmac.New(sha512.New, []byte(outerHashKey))
+		mac.Write(inner)
+		expected := mac.Sum(nil)[:DefaultKeySize/8]
+
+		if !bytes.Equal(hash[hdr.size+hdr.saltLen:], expected) {
+			t.Errorf("expected the sub-key to be the outer HMAC of the derived key")
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if h.Verify([]byte("NotMyPassword"), hash) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		// the default hasher reads the outer hash from the header.
+		if !Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		// hashes without an outer hash are verified without one, but need rehashing.
+		plain := mustHash(t, pwd)
+		if !h.Verify(pwd, plain) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if !h.NeedsRehash(plain) || h.NeedsRehash(hash) {
+			t.Errorf("expected only the hash without an outer hash to need rehashing")
+		}
+	})
+
+	t.Run("Round Trip", func(t *testing.T) {
+		s, err := encodeString(hash)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		if !strings.Contains(s, ",oh=sha512$") {
+			t.Errorf("expected the PHC string to contain the outer hash, but got '%s'", s)
+		}
+
+		if !h.VerifyString(pwd, s) {
+			t.Errorf("expected PHC string to be valid")
+		}
+
+		r, _ := ToRecord(hash)
+		if r.OuterHash != "sha512" {
+			t.Errorf("expected the record's outer hash to be 'sha512' but got '%s'", r.OuterHash)
+		}
+
+		if out, _ := FromRecord(r); !bytes.Equal(out, hash) {
+			t.Errorf("expected the record to convert back to the same hash")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, opts := range map[string][]Option{
+			"Unsupported":  {WithOuterHash(237)},
+			"Short Digest": {WithOuterHash(HashSHA256)},
+		} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, 512, HashSHA512, opts...)
+			if err != ErrInvalidOuterHash {
+				t.Errorf("%s: expected '%v' but got '%v'", name, ErrInvalidOuterHash, err)
+			}
+		}
+
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithOuterHash(HashSHA512), WithOutputFormatVersion(1))
+		if err != ErrInvalidFormatVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormatVersion, err)
+		}
+	})
+}
+
+func TestWithTimestamp(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	created := time.Date(2020, 6, 11, 12, 0, 0, 0, time.UTC)
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithTimestamp(true))
+	h.(*hasher).now = func() time.Time { return created }
+
+	hash, _ := h.Hash(pwd)
+
+	t.Run("Inspect", func(t *testing.T) {
+		info, err := Inspect(hash)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			return
+		}
+
+		if !info.CreatedAt.Equal(created) {
+			t.Errorf("expected a creation time of %v, but got %v", created, info.CreatedAt)
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		s, _ := h.HashString(pwd)
+		if !h.VerifyString(pwd, s) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Absent", func(t *testing.T) {
+		info, _ := Inspect(mustHash(t, pwd))
+		if !info.CreatedAt.IsZero() {
+			t.Errorf("expected no creation time, but got %v", info.CreatedAt)
+		}
+	})
+}
+
+func TestWithMaxAge(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	now := time.Date(2020, 6, 11, 12, 0, 0, 0, time.UTC)
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithTimestamp(true), WithMaxAge(24*time.Hour))
+	h.(*hasher).now = func() time.Time { return now }
+
+	hash, _ := h.Hash(pwd)
+	if h.NeedsRehash(hash) {
+		t.Errorf("didn't expect hash to need rehashing")
+	}
+
+	t.Run("Expired", func(t *testing.T) {
+		now = now.Add(25 * time.Hour)
+		if !h.NeedsRehash(hash) {
+			t.Errorf("expected hash to need rehashing")
+		}
+	})
+
+	t.Run("No Timestamp", func(t *testing.T) {
+		if !h.NeedsRehash(mustHash(t, pwd)) {
+			t.Errorf("expected hash to need rehashing")
+		}
+	})
+
+	t.Run("Invalid Max Age", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMaxAge(-time.Hour))
+		if err != ErrInvalidMaxAge {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidMaxAge, err)
+		}
+	})
+}
+
+func TestWithKeyLengthInHeader(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	padding := []byte{0, 0, 0, 0, 0, 0, 0, 0}
+
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithKeyLengthInHeader(true))
+	hash, _ := hasher.Hash(pwd)
+
+	t.Run("Header", func(t *testing.T) {
+		hdr, _ := scanHeader(hash)
+		if hdr.flags&flagKeyLen == 0 {
+			t.Errorf("expected the key length flag to be set")
+		}
+
+		if hdr.keyLen != DefaultKeySize/8 {
+			t.Errorf("expected a key length of %d, but got %d", DefaultKeySize/8, hdr.keyLen)
+		}
+	})
+
+	t.Run("Trailing Bytes", func(t *testing.T) {
+		padded := append(append([]byte{}, hash...), padding...)
+		if !hasher.Verify(pwd, padded) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		info, _ := Inspect(padded)
+		if info.KeySize != DefaultKeySize {
+			t.Errorf("expected a key size of %d, but got %d", DefaultKeySize, info.KeySize)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		if hasher.Verify(pwd, hash[:len(hash)-1]) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		err := hasher.VerifyWithReason(pwd, hash[:len(hash)-1])
+		if err != ErrKeyLengthMismatch || !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("expected '%v' but got '%v'", ErrKeyLengthMismatch, err)
+		}
+
+		// a truncated salt is not a key length mismatch.
+		hdr, _ := scanHeader(hash)
+		if err := hasher.VerifyWithReason(pwd, hash[:hdr.size+1]); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+
+	t.Run("Remainder", func(t *testing.T) {
+		// without the key length, trailing bytes are read as part of the sub-key.
+		padded := append(mustHash(t, pwd), padding...)
+		if hasher.Verify(pwd, padded) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		if !hasher.Verify(pwd, mustHash(t, pwd)) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Version 1", func(t *testing.T) {
+		// version 1 hashes can't declare the key length, so are always read as the remainder.
+		salt := make([]byte, DefaultSaltSize/8)
+		subKey, _ := DeriveKey(pwd, salt, DefaultIterationCount, DefaultKeySize/8, DefaultHashKey)
+
+		hash := make([]byte, headerSizeV1+len(salt)+len(subKey))
+		writeHeader(hash, header{
+			version: 1,
+			hashKey: DefaultHashKey,
+			iterCnt: DefaultIterationCount,
+			saltLen: len(salt),
+		})
+		copy(hash[headerSizeV1+len(salt):], subKey)
+
+		if !hasher.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+}
+
+func TestWithConcurrencyLimit(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithConcurrencyLimit(1))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hash, err := h.HashContext(context.Background(), pwd)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	ok, err := h.VerifyContext(context.Background(), pwd, hash)
+	if !ok || err != nil {
+		t.Errorf("expected hash to be valid, but got '%v'", err)
+	}
+
+	t.Run("No Free Slots", func(t *testing.T) {
+		// occupy the only slot.
+		release, _ := h.(*hasher).acquire(context.Background())
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := h.HashContext(ctx, pwd)
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected '%v' but got '%v'", context.DeadlineExceeded, err)
+		}
+
+		ok, err := h.VerifyContext(ctx, pwd, hash)
+		if ok || err != context.DeadlineExceeded {
+			t.Errorf("expected '%v' but got '%v'", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("Invalid Limit", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithConcurrencyLimit(0))
+		if err != ErrInvalidConcurrency {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidConcurrency, err)
+		}
+	})
+}
+
+func TestWithAllowedAlgorithms(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	sha256Hash := mustHash(t, pwd)
+
+	sha512Hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512)
+	sha512Hash, _ := sha512Hasher.Hash(pwd)
+
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithAllowedAlgorithms(HashSHA512))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if !h.Verify(pwd, sha512Hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	if h.Verify(pwd, sha256Hash) {
+		t.Errorf("expected hash to be invalid")
+	}
+
+	if err := h.VerifyWithReason(pwd, sha256Hash); err != ErrAlgorithmNotAllowed {
+		t.Errorf("expected '%v' but got '%v'", ErrAlgorithmNotAllowed, err)
+	}
+
+	t.Run("Invalid Allow-List", func(t *testing.T) {
+		for name, keys := range map[string][]int{
+			"Empty":        {},
+			"Unsupported":  {HashSHA512, 237},
+			"Excludes Own": {HashSHA256},
+		} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithAllowedAlgorithms(keys...))
+			if err != ErrInvalidAllowedAlgorithms {
+				t.Errorf("%s: expected '%v' but got '%v'", name, ErrInvalidAllowedAlgorithms, err)
+			}
+		}
+	})
+}
+
+func TestWithAlgorithmSunset(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	sha256Hash := mustHash(t, pwd)
+	sunset := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithAlgorithmSunset(HashSHA256, sunset))
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	sha512Hash, _ := h.Hash(pwd)
+
+	now := sunset.Add(-time.Second)
+	h.(*hasher).now = func() time.Time { return now }
+
+	if !h.Verify(pwd, sha256Hash) {
+		t.Errorf("expected hash to be valid before the sunset")
+	}
+
+	if !h.NeedsRehash(sha256Hash) {
+		t.Errorf("expected hash to need rehashing before the sunset")
+	}
+
+	now = sunset.Add(time.Second)
+
+	if err := h.VerifyWithReason(pwd, sha256Hash); err != ErrAlgorithmSunset {
+		t.Errorf("expected '%v' but got '%v'", ErrAlgorithmSunset, err)
+	}
+
+	if !h.Verify(pwd, sha512Hash) {
+		t.Errorf("expected hash using the hasher's algorithm to be valid after the sunset")
+	}
+
+	t.Run("Invalid Sunset", func(t *testing.T) {
+		for name, key := range map[string]int{
+			"Unsupported": 237,
+			"Own":         HashSHA512,
+		} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithAlgorithmSunset(key, sunset))
+			if err != ErrInvalidAlgorithmSunset {
+				t.Errorf("%s: expected '%v' but got '%v'", name, ErrInvalidAlgorithmSunset, err)
+			}
+		}
+	})
+}
+
+func TestWithContext(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	login, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithContext([]byte("login")))
+	recovery, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithContext([]byte("recovery")))
+
+	hash, _ := login.Hash(pwd)
+
+	t.Run("Header", func(t *testing.T) {
+		info, _ := Inspect(hash)
+		if !info.Context {
+			t.Errorf("expected the context flag to be set")
+		}
+
+		if bytes.Contains(hash, []byte("login")) {
+			t.Errorf("didn't expect the context to be stored")
+		}
+	})
+
+	if !login.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	if login.NeedsRehash(hash) {
+		t.Errorf("didn't expect hash to need rehashing")
+	}
+
+	t.Run("Different Context", func(t *testing.T) {
+		if err := recovery.VerifyWithReason(pwd, hash); err != ErrPasswordMismatch {
+			t.Errorf("expected '%v' but got '%v'", ErrPasswordMismatch, err)
+		}
+	})
+
+	t.Run("No Context", func(t *testing.T) {
+		if err := defaultHasher.VerifyWithReason(pwd, hash); err != ErrContextMismatch {
+			t.Errorf("expected '%v' but got '%v'", ErrContextMismatch, err)
+		}
+
+		if err := login.VerifyWithReason(pwd, mustHash(t, pwd)); err != ErrContextMismatch {
+			t.Errorf("expected '%v' but got '%v'", ErrContextMismatch, err)
+		}
+
+		if !login.NeedsRehash(mustHash(t, pwd)) {
+			t.Errorf("expected hash to need rehashing")
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		s, _ := login.HashString(pwd)
+		if !login.VerifyString(pwd, s) {
+			t.Errorf("expected string to be valid")
+		}
+	})
+
+	t.Run("Empty Context", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithContext(nil))
+		if err != ErrInvalidContext {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidContext, err)
+		}
+	})
+}
+
+func TestWithSaltPosition(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithKeyLengthInHeader(true))
+	hash, _ := h.Hash(pwd)
+
+	// rearrange the hash into the legacy layout, with the sub-key before the salt.
+	hdr, _ := scanHeader(hash)
+	salt := hash[hdr.size : hdr.size+hdr.saltLen]
+	legacy := append(append(append([]byte{}, hash[:hdr.size]...), hdr.subKey(hash)...), salt...)
+
+	legacyHasher, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithSaltPosition(SaltAfterKey))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	t.Run("Salt After Key", func(t *testing.T) {
+		if !legacyHasher.Verify(pwd, legacy) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if legacyHasher.Verify([]byte("wrong"), legacy) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		if h.Verify(pwd, legacy) {
+			t.Errorf("expected hash to be invalid with the default position")
+		}
+	})
+
+	t.Run("Salt Before Key", func(t *testing.T) {
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithSaltPosition(SaltBeforeKey))
+		if !hasher.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("No Key Length", func(t *testing.T) {
+		err := legacyHasher.VerifyWithReason(pwd, mustHash(t, pwd))
+		if !errors.Is(err, ErrCorruptHash) {
+			t.Errorf("expected '%v' but got '%v'", ErrCorruptHash, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithSaltPosition(2))
+		if err != ErrInvalidSaltPosition {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidSaltPosition, err)
+		}
+	})
+}
+
+func TestWithRejectNullBytes(t *testing.T) {
+	pwd := []byte("My\x00TestPassword")
+
+	t.Run("Reject", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithRejectNullBytes(true))
+
+		hash, err := h.Hash(pwd)
+		if err != ErrNullByte {
+			t.Errorf("expected '%v' but got '%v'", ErrNullByte, err)
+		}
+
+		if hash != nil {
+			t.Errorf("expected a nil hash")
+		}
+
+		if _, err := h.HashString(pwd); err != ErrNullByte {
+			t.Errorf("expected '%v' but got '%v'", ErrNullByte, err)
+		}
+
+		if _, err := h.Hash([]byte("MyTestPassword")); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+	})
+
+	t.Run("Preserve", func(t *testing.T) {
+		hash := mustHash(t, pwd)
+		if !Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		// the null byte, and everything after it, is part of the password.
+		if Verify([]byte("My"), hash) {
+			t.Errorf("expected hash to be invalid for the truncated password")
+		}
+	})
+}
+
+func TestWithMinIterationRatio(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(2000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinIterationRatio(0.5))
+
+	testCases := []struct {
+		Name     string
+		IterCnt  int
+		Expected error
+	}{
+		{Name: "Current", IterCnt: 2000, Expected: nil},
+		{Name: "At Ratio", IterCnt: 1000, Expected: nil},
+		{Name: "Below Ratio", IterCnt: 999, Expected: ErrHashTooWeak},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			old, _ := New(tc.IterCnt, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+			hash, _ := old.Hash(pwd)
+
+			if err := h.VerifyWithReason(pwd, hash); err != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+			}
+
+			if ok := h.Verify(pwd, hash); ok != (tc.Expected == nil) {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected == nil, ok)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, r := range []float64{-0.1, 1.1, math.NaN()} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinIterationRatio(r))
+			if err != ErrInvalidIterationRatio {
+				t.Errorf("%v: expected '%v' but got '%v'", r, ErrInvalidIterationRatio, err)
+			}
+		}
+	})
+}
+
+func TestWithOutputFormatVersion(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512, WithOutputFormatVersion(1))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	hash, _ := h.Hash(pwd)
+
+	t.Run("Version 1", func(t *testing.T) {
+		if hash[0] != formatMarker {
+			t.Errorf("expected '%v' at the start of the hash but got '%v'", formatMarker, hash[0])
+		}
+
+		if len(hash) != OutputLenVersion(1, DefaultSaltSize, DefaultKeySize) {
+			t.Errorf("expected %d bytes, but got %d", OutputLenVersion(1, DefaultSaltSize, DefaultKeySize), len(hash))
+		}
+
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+
+	t.Run("Needs Rehash", func(t *testing.T) {
+		if h.NeedsRehash(hash) {
+			t.Errorf("didn't expect a version 1 hash to need rehashing")
+		}
+
+		current, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512)
+		if !current.NeedsRehash(hash) {
+			t.Errorf("expected a version 1 hash to need rehashing by the current version")
+		}
+
+		if !h.Verify(pwd, mustHash(t, pwd)) {
+			t.Errorf("expected a version 2 hash to be valid")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		testCases := []struct {
+			Name string
+			Opts []Option
+		}{
+			{Name: "Unsupported Version", Opts: []Option{WithOutputFormatVersion(CompactHeaderVersion + 1)}},
+			{Name: "Zero Version", Opts: []Option{WithOutputFormatVersion(0)}},
+			{Name: "Pre-Hash", Opts: []Option{WithOutputFormatVersion(1), WithPreHash(HashSHA512)}},
+			{Name: "Timestamp", Opts: []Option{WithOutputFormatVersion(1), WithTimestamp(true)}},
+			{Name: "Key Length", Opts: []Option{WithOutputFormatVersion(1), WithKeyLengthInHeader(true)}},
+			{Name: "Context", Opts: []Option{WithOutputFormatVersion(1), WithContext([]byte("login"))}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, tc.Opts...)
+				if err != ErrInvalidFormatVersion {
+					t.Errorf("expected '%v' but got '%v'", ErrInvalidFormatVersion, err)
+				}
+			})
+		}
+	})
+}
+
+func TestWithAdaptiveCost(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithAdaptiveCost(10*time.Millisecond, 2))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	hashAt := func(expected int) {
+		t.Helper()
+
+		hash, err := h.Hash(pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if info, _ := Inspect(hash); info.Iterations != expected {
+			t.Errorf("expected %d iterations, but got %d", expected, info.Iterations)
+		}
+
+		if !h.Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+	}
+
+	// new hashes use the adaptive iteration count, once it's been adjusted.
+	hashAt(DefaultIterationCount)
+
+	cost := h.(*hasher).adaptive
+	cost.observe(DefaultIterationCount, 0)
+	cost.observe(DefaultIterationCount, 0)
+	hashAt(1250)
+
+	if p := h.Params(); p.IterationCount != DefaultIterationCount {
+		t.Errorf("expected Params to report %d iterations, but got %d", DefaultIterationCount, p.IterationCount)
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, opt := range map[string]Option{
+			"Zero Target":   WithAdaptiveCost(0, 10),
+			"Zero Interval": WithAdaptiveCost(time.Millisecond, 0),
+		} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, opt)
+			if err != ErrInvalidAdaptiveCost {
+				t.Errorf("%s: expected '%v' but got '%v'", name, ErrInvalidAdaptiveCost, err)
+			}
+		}
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithTimeout(10*time.Millisecond))
+
+	hash, err := h.Hash(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !h.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Malicious Hash", func(t *testing.T) {
+		// a hash with a pathological iteration count would take minutes to verify.
+		malicious := append([]byte{}, hash...)
+		writeHeaderValue(malicious, 7, 1<<30)
+
+		start := time.Now()
+		if h.Verify(pwd, malicious) {
+			t.Errorf("expected hash to be invalid")
+		}
+
+		var interrupted *InterruptedError
+		if err := h.VerifyWithReason(pwd, malicious); !errors.As(err, &interrupted) {
+			t.Errorf("expected an interrupted error, but got '%v'", err)
+		}
+
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected verification to stop after the timeout, but took %v", elapsed)
+		}
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		slow, _ := New(1<<30, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithTimeout(10*time.Millisecond))
+
+		var interrupted *InterruptedError
+		if _, err := slow.Hash(pwd); !errors.As(err, &interrupted) {
+			t.Errorf("expected an interrupted error, but got '%v'", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithTimeout(-time.Second))
+		if err != ErrInvalidTimeout {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidTimeout, err)
+		}
+	})
+}
+
+func TestWithMinHashDuration(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(1000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinHashDuration(50*time.Millisecond))
+
+	start := time.Now()
+	hash, err := h.Hash(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("expected Hash to take at least '%v' but took '%v'", 50*time.Millisecond, d)
+	}
+
+	if !h.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Cancelled", func(t *testing.T) {
+		h, _ := New(1000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinHashDuration(time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := h.HashContext(ctx, pwd); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		if d := time.Since(start); d > 10*time.Second {
+			t.Errorf("expected the padding to stop with the context, but took '%v'", d)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinHashDuration(-time.Second))
+		if err != ErrInvalidMinHashDuration {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidMinHashDuration, err)
+		}
+	})
+}
+
+func TestWithIgnoreUnknownFormatVersion(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hash := mustHash(t, pwd)
+
+	// a hash from a future release, with the current layout.
+	future := append([]byte{}, hash...)
+	future[1] = 0xFE
+
+	t.Run("Default", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+		if err := h.VerifyWithReason(pwd, future); err != ErrUnsupportedVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedVersion, err)
+		}
+	})
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithIgnoreUnknownFormatVersion(true))
+
+	t.Run("Enabled", func(t *testing.T) {
+		if !h.Verify(pwd, future) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if future[1] != 0xFE {
+			t.Errorf("expected the hash not to be modified")
+		}
+
+		if h.Verify([]byte("wrong"), future) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Unknown Flags", func(t *testing.T) {
+		flagged := append([]byte{}, future...)
+		flagged[2] = 0x80
+
+		if err := h.VerifyWithReason(pwd, flagged); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+
+	t.Run("Older Version", func(t *testing.T) {
+		older := append([]byte{}, hash...)
+		older[1] = 0
+
+		if err := h.VerifyWithReason(pwd, older); err != ErrUnsupportedVersion {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedVersion, err)
+		}
+	})
+}
+
+func TestWithParameterWindow(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	min := Config{IterationCount: 1000, SaltSize: 128, KeySize: 256}
+	max := Config{IterationCount: 10000, SaltSize: 256, KeySize: 512}
+
+	h, err := New(2000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithParameterWindow(min, max))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	hashWith := func(iterCnt, saltSize, keySize int) []byte {
+		other, _ := New(iterCnt, saltSize, keySize, HashSHA512)
+		return mustHashWith(t, other, pwd)
+	}
+
+	testCases := []struct {
+		Name     string
+		Hash     []byte
+		Field    string
+		Expected error
+	}{
+		{Name: "Within", Hash: hashWith(5000, 256, 512), Expected: nil},
+		{Name: "Lower Bounds", Hash: hashWith(1000, 128, 256), Expected: nil},
+		{Name: "Upper Bounds", Hash: hashWith(10000, 256, 256), Expected: nil},
+		{Name: "Too Few Iterations", Hash: hashWith(999, 128, 256), Field: "iterations", Expected: ErrHashTooWeak},
+		{Name: "Too Many Iterations", Hash: hashWith(10001, 128, 256), Field: "iterations", Expected: ErrHashTooStrong},
+		{Name: "Salt Too Large", Hash: hashWith(1000, 264, 256), Field: "saltSize", Expected: ErrHashTooStrong},
+		{Name: "Key Too Large", Hash: hashWith(1000, 128, 1024), Field: "keySize", Expected: ErrHashTooStrong},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := h.VerifyWithReason(pwd, tc.Hash)
+			if !errors.Is(err, tc.Expected) {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+			}
+
+			var windowErr *WindowError
+			if errors.As(err, &windowErr) != (tc.Field != "") {
+				t.Fatalf("expected a *WindowError to be returned only when outside the window, but got '%v'", err)
+			}
+
+			if windowErr != nil && windowErr.Field != tc.Field {
+				t.Errorf("expected '%s' but got '%s'", tc.Field, windowErr.Field)
+			}
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		err := h.VerifyWithReason(pwd, hashWith(500, 128, 256))
+		if expected := "hasher: iterations 500 is outside the window [1000, 10000]"; err.Error() != expected {
+			t.Errorf("expected '%s' but got '%s'", expected, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		testCases := []struct {
+			Name     string
+			Min, Max Config
+		}{
+			{Name: "Negative", Min: Config{IterationCount: -1}},
+			{Name: "Inverted", Min: Config{SaltSize: 256}, Max: Config{SaltSize: 128}},
+			{Name: "Hash Key", Min: Config{HashKey: HashSHA256}},
+			{Name: "Excludes Hasher", Min: Config{IterationCount: 5000}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				_, err := New(2000, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithParameterWindow(tc.Min, tc.Max))
+				if err != ErrInvalidParameterWindow {
+					t.Errorf("expected '%v' but got '%v'", ErrInvalidParameterWindow, err)
+				}
+			})
+		}
+	})
+}
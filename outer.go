@@ -0,0 +1,20 @@
+package hasher
+
+import (
+	"crypto/hmac"
+)
+
+// outerHashKey is the fixed key of the outer HMAC, see WithOuterHash. The outer hash
+// adds algorithm agility, rather than a secret, so the key doesn't need to be secret.
+const outerHashKey = "adaptive-password-hasher outer hash"
+
+// passes the sub-key through the outer HMAC, using the algorithm for the given hash key,
+// returning the first len(subKey) bytes of the result, so the sub-key's size is unchanged.
+// The caller must ensure the hash key is supported, and its digest is at least as long
+// as the sub-key.
+func outerHash(subKey []byte, hashKey int) []byte {
+	mac := hmac.New(alg(hashKey), []byte(outerHashKey))
+	mac.Write(subKey)
+
+	return mac.Sum(nil)[:len(subKey)]
+}
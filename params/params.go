@@ -0,0 +1,47 @@
+// Package params provides a Params type, describing the parameters of a hasher,
+// for accepting hashing configurations from API clients, such as a JSON request body.
+//
+// Fields are annotated with validate tags, which are compatible with
+// github.com/go-playground/validator, so web frameworks can validate incoming
+// configurations declaratively. This package doesn't depend on the validator,
+// so it's only needed by applications which use it.
+package params
+
+import (
+	hasher "github.com/reecerussell/adaptive-password-hasher"
+)
+
+// MinIterationCount is the minimum iteration count accepted by Params.
+const MinIterationCount = 1000
+
+// Params contains the parameters used to configure a Hasher.
+type Params struct {
+	// IterationCount is the iteration count, which must be at least MinIterationCount.
+	IterationCount int `json:"iterationCount" validate:"required,min=1000"`
+
+	// SaltSize and KeySize are recognised as number of bits, and must be a multiple of 8.
+	SaltSize int `json:"saltSize" validate:"required,min=64,max=1024"`
+	KeySize  int `json:"keySize" validate:"required,min=128,max=1024"`
+
+	// HashKey is the hash key of the algorithm, either HashSHA256 or HashSHA512.
+	HashKey int `json:"hashKey" validate:"required,oneof=1 2"`
+}
+
+// Default returns Params with the hasher package's default values.
+func Default() Params {
+	return Params{
+		IterationCount: hasher.DefaultIterationCount,
+		SaltSize:       hasher.DefaultSaltSize,
+		KeySize:        hasher.DefaultKeySize,
+		HashKey:        hasher.DefaultHashKey,
+	}
+}
+
+// ToHasher returns a new Hasher, configured with the params and given options.
+//
+// The params are validated by hasher.New, regardless of whether they've been
+// validated using their tags, so a non-nil error will be returned if any of them are
+// invalid. As the tags can't express it, this includes sizes which aren't a multiple of 8.
+func (p Params) ToHasher(opts ...hasher.Option) (hasher.Hasher, error) {
+	return hasher.New(p.IterationCount, p.SaltSize, p.KeySize, p.HashKey, opts...)
+}
@@ -0,0 +1,30 @@
+package params
+
+import (
+	"errors"
+	"testing"
+
+	hasher "github.com/reecerussell/adaptive-password-hasher"
+)
+
+func TestToHasher(t *testing.T) {
+	h, err := Default().ToHasher()
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	if h.Algorithm() != hasher.DefaultHashKey {
+		t.Errorf("expected '%d' but got '%d'", hasher.DefaultHashKey, h.Algorithm())
+	}
+
+	t.Run("Invalid Params", func(t *testing.T) {
+		p := Default()
+		p.SaltSize = 100
+
+		_, err := p.ToHasher()
+		if !errors.Is(err, hasher.ErrInvalidSaltSize) {
+			t.Errorf("expected '%v' but got '%v'", hasher.ErrInvalidSaltSize, err)
+		}
+	})
+}
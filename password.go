@@ -0,0 +1,40 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// ErrInvalidEntropy is returned by GeneratePassword when the requested
+// entropy is not positive.
+var ErrInvalidEntropy = errors.New("entropy must be positive")
+
+// passwordAlphabet is the set of characters used by GeneratePassword. It contains
+// 64 characters, so each character carries exactly 6 bits of entropy.
+const passwordAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// GeneratePassword returns a random password, generated using crypto/rand, with
+// at least the given number of bits of entropy. This is useful for generating
+// temporary passwords, before they're hashed.
+//
+// Passwords are made up of the characters A-Z, a-z, 0-9, '-' and '_', each of
+// which carries 6 bits of entropy, so the password's length is entropyBits / 6,
+// rounded up.
+func GeneratePassword(entropyBits int) (string, error) {
+	if entropyBits < 1 {
+		return "", ErrInvalidEntropy
+	}
+
+	buf := make([]byte, (entropyBits+5)/6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	// as the alphabet has 64 characters, using the low 6
+	// bits of each byte selects characters without bias.
+	for i, b := range buf {
+		buf[i] = passwordAlphabet[b&63]
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,48 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	lengths := map[int]int{
+		1:   1,
+		6:   1,
+		7:   2,
+		128: 22,
+	}
+
+	for entropy, length := range lengths {
+		pwd, err := GeneratePassword(entropy)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			continue
+		}
+
+		if len(pwd) != length {
+			t.Errorf("expected a length of %d for %d bits, but got %d", length, entropy, len(pwd))
+		}
+
+		for _, c := range pwd {
+			if !strings.ContainsRune(passwordAlphabet, c) {
+				t.Errorf("unexpected character '%c'", c)
+			}
+		}
+	}
+
+	t.Run("Random", func(t *testing.T) {
+		a, _ := GeneratePassword(128)
+		b, _ := GeneratePassword(128)
+		if a == b {
+			t.Errorf("expected passwords to differ")
+		}
+	})
+
+	t.Run("Invalid Entropy", func(t *testing.T) {
+		_, err := GeneratePassword(0)
+		if err != ErrInvalidEntropy {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidEntropy, err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package hasher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// ApplyPepper returns the password peppered with the given secret, which is the
+// HMAC-SHA256 of the password, keyed by the pepper, encoded as unpadded, standard
+// base64, so it never contains a null byte, and is a valid password whichever options
+// a hasher is configured with, such as WithRejectNullBytes. Hashing the peppered
+// password, rather than the password itself, means a leaked hash can't be cracked
+// without the pepper, which should be stored away from the hashes, such as in a KMS.
+//
+// The pepper isn't recorded in the hash, so hashes must be verified with the same
+// pepper, or with VerifyWithPeppers while the pepper is being rotated.
+func ApplyPepper(pwd, pepper []byte) []byte {
+	mac := hmac.New(sha256.New, pepper)
+	mac.Write(pwd)
+
+	sum := mac.Sum(nil)
+	defer wipe(sum)
+
+	out := make([]byte, base64.RawStdEncoding.EncodedLen(len(sum)))
+	base64.RawStdEncoding.Encode(out, sum)
+
+	return out
+}
+
+// VerifyWithPeppers verifies the password, peppered with each of the given peppers,
+// see ApplyPepper, against the hash, returning true if any of them match. This covers
+// rotating the pepper, before every hash has been rehashed with the new one, as each
+// hash can be verified with both the new and the old pepper.
+//
+// Every pepper is tried, even once one has matched, so the time taken doesn't reveal
+// which pepper matched, only the number of peppers. With no peppers, false is returned.
+func (h *hasher) VerifyWithPeppers(pwd, hash []byte, peppers ...[]byte) bool {
+	return verifyWithPeppers(h, pwd, hash, peppers)
+}
+
+// verifies the password against the hash using the Hasher, with each pepper.
+func verifyWithPeppers(h Hasher, pwd, hash []byte, peppers [][]byte) bool {
+	ok := false
+	for _, pepper := range peppers {
+		peppered := ApplyPepper(pwd, pepper)
+
+		// not short-circuited, so every pepper is verified.
+		ok = h.Verify(peppered, hash) || ok
+
+		wipe(peppered)
+	}
+
+	return ok
+}
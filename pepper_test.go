@@ -0,0 +1,76 @@
+package hasher
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestVerifyWithPeppers(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	oldPepper, newPepper := []byte("old-pepper"), []byte("new-pepper")
+
+	h, _ := New(1000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash, err := h.Hash(ApplyPepper(pwd, oldPepper))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	testCases := []struct {
+		Name     string
+		Pwd      []byte
+		Peppers  [][]byte
+		Expected bool
+	}{
+		{"Old Pepper", pwd, [][]byte{oldPepper}, true},
+		{"New Then Old", pwd, [][]byte{newPepper, oldPepper}, true},
+		{"Old Then New", pwd, [][]byte{oldPepper, newPepper}, true},
+		{"New Pepper Only", pwd, [][]byte{newPepper}, false},
+		{"Wrong Password", []byte("wrong"), [][]byte{newPepper, oldPepper}, false},
+		{"No Peppers", pwd, nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if ok := h.VerifyWithPeppers(tc.Pwd, hash, tc.Peppers...); ok != tc.Expected {
+				t.Errorf("expected '%v' but got '%v'", tc.Expected, ok)
+			}
+		})
+	}
+
+	t.Run("Unpeppered", func(t *testing.T) {
+		if h.Verify(pwd, hash) {
+			t.Errorf("expected the peppered hash not to match the plain password")
+		}
+	})
+
+	t.Run("Reject Null Bytes", func(t *testing.T) {
+		h, _ := New(1, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithRejectNullBytes(true))
+
+		// a raw HMAC contains a null byte for about 1 in 8 peppers.
+		for i := 0; i < 500; i++ {
+			peppered := ApplyPepper(pwd, []byte("pepper-"+strconv.Itoa(i)))
+			if bytes.IndexByte(peppered, 0) >= 0 {
+				t.Fatalf("expected the peppered password not to contain a null byte")
+			}
+
+			hash, err := h.Hash(peppered)
+			if err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+
+			if !h.Verify(peppered, hash) {
+				t.Errorf("expected hash to be valid")
+			}
+		}
+	})
+
+	t.Run("Noop", func(t *testing.T) {
+		var n NoopHasher
+		hash, _ := n.Hash(ApplyPepper(pwd, oldPepper))
+
+		if !n.VerifyWithPeppers(pwd, hash, newPepper, oldPepper) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+}
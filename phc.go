@@ -0,0 +1,267 @@
+package hasher
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// phcPrefix is the prefix of the algorithm identifier in a PHC string.
+const phcPrefix = "pbkdf2-"
+
+// errInvalidString is returned when a string is not in the format produced by HashString.
+var errInvalidString = errors.New("string is not in the PHC format")
+
+// maxTextIterations is the largest iteration count accepted by parseIterations, so an
+// untrusted string can't make a verifier derive a key for minutes. It's well above the
+// counts recommended for pbkdf2, leaving room for them to grow.
+const maxTextIterations = 10000000
+
+// errInvalidIterations is returned when a textual iteration count can't be parsed.
+var errInvalidIterations = fmt.Errorf("iteration count must be a positive decimal number, at most %d", maxTextIterations)
+
+// HashString hashes the given password using the default hasher, returning the
+// hash as a PHC string, see Hasher.HashString.
+func HashString(pwd []byte) (string, error) {
+	return defaultHasher.HashString(pwd)
+}
+
+// VerifyString attempts to verify the password against a PHC string using the
+// default hasher, see Hasher.VerifyString.
+func VerifyString(pwd []byte, s string) bool {
+	return defaultHasher.VerifyString(pwd, s)
+}
+
+// HashString hashes the given password in the same way as Hash, returning
+// the hash as a string in the PHC string format:
+//
+//	$pbkdf2-<algorithm>$i=<iterations>[,ph=<algorithm>][,t=<created>][,c=1][,oh=<algorithm>][,id=1]$<salt>$<sub-key>
+//
+// where the salt and sub-key are encoded using unpadded, standard base64, the
+// "ph" parameter is the pre-hash algorithm, if WithPreHash was used, the "t"
+// parameter is the creation time in unix seconds, if WithTimestamp was used, the
+// "c" parameter is present if WithContext was used, the "oh" parameter is the
+// outer hash algorithm, if WithOuterHash was used, and the "id" parameter is present
+// if the hash was derived with an identity, see HashWithIdentity.
+//
+// A non-nil error will be returned if a salt could not be generated.
+func (h *hasher) HashString(pwd []byte) (string, error) {
+	hash, err := h.Hash(pwd)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeString(hash)
+}
+
+// VerifyString verifies the password against a hash produced by HashString,
+// in the same way as Verify. The salt and sub-key may be encoded using either
+// standard or URL-safe base64, with or without padding.
+//
+// Will return false if the password doesn't match, or the string is invalid.
+func (h *hasher) VerifyString(pwd []byte, s string) bool {
+	hash, err := decodeString(s)
+	if err != nil {
+		return false
+	}
+
+	return h.Verify(pwd, hash)
+}
+
+// encodes a hash as a PHC string.
+func encodeString(hash []byte) (string, error) {
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := algNames[hdr.hashKey]
+	if !ok {
+		return "", ErrUnsupportedHashKey
+	}
+
+	params, err := encodeParams(hdr)
+	if err != nil {
+		return "", err
+	}
+
+	salt := hash[hdr.size : hdr.size+hdr.saltLen]
+	subKey := hdr.subKey(hash)
+
+	return fmt.Sprintf("$%s%s$%s$%s$%s",
+		phcPrefix, name, params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(subKey)), nil
+}
+
+// encodes the parameters of a hash with the scanned header, as used in a PHC string.
+func encodeParams(hdr header) (string, error) {
+	params := "i=" + strconv.Itoa(hdr.iterCnt)
+	if hdr.flags&flagPreHash != 0 {
+		preHashName, ok := algNames[hdr.preHash]
+		if !ok {
+			return "", ErrUnsupportedHashKey
+		}
+
+		params += ",ph=" + preHashName
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		params += ",t=" + strconv.FormatInt(hdr.created, 10)
+	}
+
+	if hdr.flags&flagContext != 0 {
+		params += ",c=1"
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		outerName, ok := algNames[hdr.outerHash]
+		if !ok {
+			return "", ErrUnsupportedHashKey
+		}
+
+		params += ",oh=" + outerName
+	}
+
+	if hdr.flags&flagIdentity != 0 {
+		params += ",id=1"
+	}
+
+	return params, nil
+}
+
+// decodes a PHC string into a hash, in the current format version.
+func decodeString(s string) ([]byte, error) {
+	// the leading '$' results in an empty first field.
+	fields := strings.Split(s, "$")
+	if len(fields) != 5 || fields[0] != "" || !strings.HasPrefix(fields[1], phcPrefix) {
+		return nil, errInvalidString
+	}
+
+	hdr := header{version: HeaderVersion}
+
+	hashKey, ok := lookupAlgName(strings.TrimPrefix(fields[1], phcPrefix))
+	if !ok {
+		return nil, ErrUnsupportedHashKey
+	}
+
+	hdr.hashKey = hashKey
+
+	for _, param := range strings.Split(fields[2], ",") {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalidString
+		}
+
+		switch kv[0] {
+		case "i":
+			iterCnt, err := parseIterations(kv[1])
+			if err != nil {
+				return nil, errInvalidString
+			}
+
+			hdr.iterCnt = iterCnt
+		case "ph":
+			preHash, ok := lookupAlgName(kv[1])
+			if !ok {
+				return nil, ErrUnsupportedHashKey
+			}
+
+			hdr.flags |= flagPreHash
+			hdr.preHash = preHash
+		case "t":
+			created, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, errInvalidString
+			}
+
+			hdr.flags |= flagTimestamp
+			hdr.created = created
+		case "c":
+			if kv[1] != "1" {
+				return nil, errInvalidString
+			}
+
+			hdr.flags |= flagContext
+		case "oh":
+			outer, ok := lookupAlgName(kv[1])
+			if !ok {
+				return nil, ErrUnsupportedHashKey
+			}
+
+			hdr.flags |= flagOuterHash
+			hdr.outerHash = outer
+		case "id":
+			if kv[1] != "1" {
+				return nil, errInvalidString
+			}
+
+			hdr.flags |= flagIdentity
+		default:
+			return nil, errInvalidString
+		}
+	}
+
+	if hdr.iterCnt == 0 {
+		// the iteration count is required.
+		return nil, errInvalidString
+	}
+
+	salt, err := decodeBase64(fields[3])
+	if err != nil {
+		return nil, errInvalidString
+	}
+
+	subKey, err := decodeBase64(fields[4])
+	if err != nil {
+		return nil, errInvalidString
+	}
+
+	hdr.saltLen = len(salt)
+
+	hash := make([]byte, hdr.len()+len(salt)+len(subKey))
+	n := writeHeader(hash, hdr)
+	copy(hash[n:], salt)
+	copy(hash[n+len(salt):], subKey)
+
+	return hash, nil
+}
+
+// parses an iteration count stored as ASCII decimal digits, as in the textual formats,
+// such as PHC strings and the modular crypt format, ignoring surrounding whitespace and
+// leading zeros. Unlike strconv.Atoi, signs are rejected, and the count must be at most
+// maxTextIterations, as the textual formats are verified without a WithParameterWindow,
+// so it also fits in a header value, and is never truncated when the hash is written.
+func parseIterations(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errInvalidIterations
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, errInvalidIterations
+		}
+	}
+
+	// 32 bits, as ints may be 32-bit, though headers store unsigned values.
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 1 || n > maxTextIterations {
+		return 0, errInvalidIterations
+	}
+
+	return int(n), nil
+}
+
+// decodes base64 data, detecting whether the standard or URL-safe
+// alphabet was used, and tolerating missing padding.
+func decodeBase64(s string) ([]byte, error) {
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.RawURLEncoding
+	}
+
+	return enc.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,217 @@
+package hasher
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHashString(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+
+	s, err := hasher.HashString(pwd)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	t.Run("Format", func(t *testing.T) {
+		prefix := fmt.Sprintf("$pbkdf2-sha256$i=%d$", DefaultIterationCount)
+		if !strings.HasPrefix(s, prefix) {
+			t.Errorf("expected '%s' to start with '%s'", s, prefix)
+		}
+
+		if strings.Contains(strings.TrimPrefix(s, prefix), "=") {
+			t.Errorf("expected '%s' to be unpadded", s)
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		if !hasher.VerifyString(pwd, s) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		if hasher.VerifyString([]byte("NotMyPassword"), s) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Pre-Hash", func(t *testing.T) {
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithPreHash(HashSHA512))
+		s, _ := hasher.HashString(pwd)
+
+		if !strings.Contains(s, ",ph=sha512$") {
+			t.Errorf("expected '%s' to contain the pre-hash parameter", s)
+		}
+
+		if !hasher.VerifyString(pwd, s) {
+			t.Errorf("expected hash to be valid")
+		}
+	})
+}
+
+func TestPackageHashString(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	s, err := HashString(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !VerifyString(pwd, s) {
+		t.Errorf("expected string to be valid")
+	}
+
+	if VerifyString([]byte("WrongPassword"), s) {
+		t.Errorf("expected string to be invalid")
+	}
+}
+
+func TestVerifyStringEncodings(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	// this salt encodes to characters which differ between the standard and
+	// URL-safe alphabets, and requires padding.
+	salt := bytes.Repeat([]byte{0xfb, 0xff}, DefaultSaltSize/16)
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithSaltSource(fixedSource{salt: salt}))
+
+	s, _ := hasher.HashString(pwd)
+	fields := strings.Split(s, "$")
+	subKey, _ := base64.RawStdEncoding.DecodeString(fields[4])
+
+	encodings := map[string]*base64.Encoding{
+		"Standard":          base64.StdEncoding,
+		"Standard Unpadded": base64.RawStdEncoding,
+		"URL":               base64.URLEncoding,
+		"URL Unpadded":      base64.RawURLEncoding,
+	}
+
+	for name, enc := range encodings {
+		t.Run(name, func(t *testing.T) {
+			fields[3] = enc.EncodeToString(salt)
+			fields[4] = enc.EncodeToString(subKey)
+			s := strings.Join(fields, "$")
+
+			if !hasher.VerifyString(pwd, s) {
+				t.Errorf("expected '%s' to be valid", s)
+			}
+		})
+	}
+
+	t.Run("Mixed Alphabets", func(t *testing.T) {
+		fields[3] = "-+" + base64.RawStdEncoding.EncodeToString(salt)[2:]
+		fields[4] = base64.RawStdEncoding.EncodeToString(subKey)
+
+		if hasher.VerifyString(pwd, strings.Join(fields, "$")) {
+			t.Errorf("expected hash to be invalid")
+		}
+	})
+
+	t.Run("Iteration Field", func(t *testing.T) {
+		fields[3] = base64.RawStdEncoding.EncodeToString(salt)
+		fields[4] = base64.RawStdEncoding.EncodeToString(subKey)
+
+		for _, iter := range []string{"i=01000", "i= 1000", "i=1000 "} {
+			params := append([]string{}, fields...)
+			params[2] = iter
+
+			if s := strings.Join(params, "$"); !hasher.VerifyString(pwd, s) {
+				t.Errorf("expected '%s' to be valid", s)
+			}
+		}
+	})
+
+	t.Run("Invalid String", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"$pbkdf2-sha256$i=1000$c2FsdA",
+			"$pbkdf2-sha1$i=1000$c2FsdA$c2FsdA",
+			"$pbkdf2-sha256$r=1000$c2FsdA$c2FsdA",
+			"$pbkdf2-sha256$i=abc$c2FsdA$c2FsdA",
+			"$pbkdf2-sha256$i=+1000$c2FsdA$c2FsdA",
+			"$pbkdf2-sha256$i=4294967296$c2FsdA$c2FsdA",
+			"$pbkdf2-sha256$i=1000$c2F*sdA$c2FsdA",
+		} {
+			if hasher.VerifyString(pwd, s) {
+				t.Errorf("expected '%s' to be invalid", s)
+			}
+		}
+	})
+}
+
+func TestParseIterations(t *testing.T) {
+	testCases := []struct {
+		Input    string
+		Expected int
+		Valid    bool
+	}{
+		{"1000", 1000, true},
+		{"0001000", 1000, true},
+		{" 1000\t", 1000, true},
+		{"10000000", 10000000, true},
+		{"010000000", 10000000, true},
+		{"", 0, false},
+		{"   ", 0, false},
+		{"0", 0, false},
+		{"000", 0, false},
+		{"+1000", 0, false},
+		{"-1000", 0, false},
+		{"1 000", 0, false},
+		{"1e3", 0, false},
+		{"0x3e8", 0, false},
+		{"1000a", 0, false},
+		{"10000001", 0, false},
+		{"2147483647", 0, false},
+		{"2147483648", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%q", tc.Input), func(t *testing.T) {
+			n, err := parseIterations(tc.Input)
+			if tc.Valid && err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+			}
+
+			if !tc.Valid && err != errInvalidIterations {
+				t.Errorf("expected '%v' but got '%v'", errInvalidIterations, err)
+			}
+
+			if n != tc.Expected {
+				t.Errorf("expected %d but got %d", tc.Expected, n)
+			}
+		})
+	}
+}
+
+func FuzzParseIterations(f *testing.F) {
+	for _, seed := range []string{"1000", "0001000", " 1000 ", "", "+1", "-1", "1e3", "10000000", "10000001", "2147483648"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := parseIterations(s)
+		if err != nil {
+			if n != 0 {
+				t.Errorf("expected 0 on error but got %d", n)
+			}
+
+			return
+		}
+
+		if n < 1 || n > maxTextIterations {
+			t.Errorf("expected a positive count of at most %d but got %d", maxTextIterations, n)
+		}
+
+		// a valid field is only digits, once trimmed, which encode n.
+		digits := strings.TrimLeft(strings.TrimSpace(s), "0")
+		if digits != strconv.Itoa(n) {
+			t.Errorf("expected %q to encode %d", s, n)
+		}
+	})
+}
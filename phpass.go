@@ -0,0 +1,98 @@
+package hasher
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidPhpass is returned when a string is not in the phpass portable hash format.
+var ErrInvalidPhpass = errors.New("string is not in the phpass portable format")
+
+const (
+	// phpassLen is the length of a phpass portable hash, which is the 3 character
+	// prefix, the cost character, an 8 character salt, and a 22 character checksum.
+	phpassLen = 34
+
+	// phpassMinCost and phpassMaxCost are the bounds of the base-2 logarithm of
+	// the iteration count, as accepted by phpass.
+	phpassMinCost = 7
+	phpassMaxCost = 30
+)
+
+// VerifyPhpass verifies the password against a phpass portable hash, as produced by
+// WordPress and phpBB, returning a flag which determines whether or not the password
+// matches the hash. The portable hash is iterated MD5, not pbkdf2, and is supported so
+// hashes can be migrated on login, as it's far too cheap to be used for new hashes.
+//
+// The hash must be in the format "$P$<cost><salt><checksum>", or "$H$" in place of
+// "$P$", where the cost is a single character, giving the base-2 logarithm of the
+// iteration count, and the checksum is the final MD5 digest. The cost and checksum are
+// encoded using the same alphabet as SHA-crypt, see VerifyCrypt.
+//
+// A non-nil error will be returned if the hash is not in the phpass portable format, or
+// ErrUnsupportedScheme if the prefix is not recognised, such as for phpass's bcrypt hashes.
+func VerifyPhpass(pwd []byte, hash string) (bool, error) {
+	// the leading '$' results in an empty first field.
+	fields := strings.SplitN(hash, "$", 3)
+	if len(fields) != 3 || fields[0] != "" {
+		return false, ErrInvalidPhpass
+	}
+
+	if fields[1] != "P" && fields[1] != "H" {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedScheme, fields[1])
+	}
+
+	if len(hash) != phpassLen {
+		return false, ErrInvalidPhpass
+	}
+
+	cost := strings.IndexByte(shaCryptAlphabet, hash[3])
+	if cost < phpassMinCost || cost > phpassMaxCost {
+		return false, ErrInvalidPhpass
+	}
+
+	salt, checksum := hash[4:12], hash[12:]
+
+	// H = MD5(salt || pwd), then H = MD5(H || pwd), 2^cost times.
+	h := md5.New()
+	h.Write([]byte(salt))
+	h.Write(pwd)
+	digest := h.Sum(nil)
+
+	for i := 0; i < 1<<cost; i++ {
+		h.Reset()
+		h.Write(digest)
+		h.Write(pwd)
+		digest = h.Sum(digest[:0])
+	}
+
+	return ConstantTimeEqual([]byte(encodePhpass(digest)), []byte(checksum)), nil
+}
+
+// encodes the digest using phpass's base64 variant, which encodes groups of three
+// bytes, read little-endian, least significant 6 bits first.
+func encodePhpass(digest []byte) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(digest); i += 3 {
+		var w uint
+		n := len(digest) - i
+		if n > 3 {
+			n = 3
+		}
+
+		// the final group may be short.
+		for j := n - 1; j >= 0; j-- {
+			w = w<<8 | uint(digest[i+j])
+		}
+
+		for j := 0; j <= n; j++ {
+			sb.WriteByte(shaCryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+
+	return sb.String()
+}
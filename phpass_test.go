@@ -0,0 +1,68 @@
+package hasher
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyPhpass(t *testing.T) {
+	// the first hash is from phpass's own test suite, the next from John the
+	// Ripper's phpass tests, which were produced by phpass's HashPassword, with
+	// cost characters from 6 to B, WordPress's default, and the rest were
+	// generated using a python port of phpass's portable hash.
+	testCases := []struct {
+		Name string
+		Pwd  string
+		Hash string
+	}{
+		{Name: "Reference", Pwd: "test12345", Hash: "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0"},
+		{Name: "Cost 6", Pwd: "JohnRipper", Hash: "$P$612345678si5M0DDyPpmRCmcltU/YW/"},
+		{Name: "Cost 7", Pwd: "JohnRipper", Hash: "$H$712345678WhEyvy1YWzT4647jzeOmo0"},
+		{Name: "Cost 9", Pwd: "thisisalongertestPW", Hash: "$P$912345678LIjjb6PhecupozNBmDndU0"},
+		{Name: "Cost B", Pwd: "JohnRipper", Hash: "$P$B12345678L6Lpt4BxNotVIMILOa9u81"},
+		{Name: "phpBB3", Pwd: "123456", Hash: "$H$9PE8jEklgZhgLmZl5.HYJAzfGCQtzi1"},
+		{Name: "WordPress Cost", Pwd: "password", Hash: "$P$BabcdEFGHbjXxaIXOaZeYjawF48BT6/"},
+		{Name: "phpBB", Pwd: "correct horse battery staple", Hash: "$H$9zyXWvutszDdmSEwu8GjT96LVZBA5O."},
+		{Name: "Empty Password", Pwd: "", Hash: "$P$7saltsaltIKdaRXd8XoAff4IfY5Hiu."},
+		{Name: "UTF-8 Password", Pwd: "pässwörd", Hash: "$P$Cu/1.Zx9qj4tGVcKOPp9TzCaxzLbFb0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := VerifyPhpass([]byte(tc.Pwd), tc.Hash)
+			if !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+			}
+
+			ok, err = VerifyPhpass([]byte(tc.Pwd+"!"), tc.Hash)
+			if ok || err != nil {
+				t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+			}
+		})
+	}
+
+	t.Run("Unsupported Scheme", func(t *testing.T) {
+		_, err := VerifyPhpass([]byte("password"), "$2a$08$Ybpd6hfH1rquUadcqgTALOjYP1X0Zk2IoiGa5tmnE5uvChs5DkIzi")
+		if !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedScheme, err)
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		for _, hash := range []string{
+			"",
+			"$P",
+			"P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0",
+			"$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L",
+			"$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L00",
+			"$P$4IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0",
+			"$P$UIQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0",
+			"$P$*IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0",
+		} {
+			ok, err := VerifyPhpass([]byte("test12345"), hash)
+			if ok || err != ErrInvalidPhpass {
+				t.Errorf("%q: expected (false, '%v') but got (%v, '%v')", hash, ErrInvalidPhpass, ok, err)
+			}
+		}
+	})
+}
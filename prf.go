@@ -0,0 +1,152 @@
+package hasher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"hash"
+	"sync"
+)
+
+// defaultPRF is HMAC-SHA256, used as the pseudorandom function of pbkdf2 by the
+// verification fast path, see deriveDefaultKey. Unlike crypto/hmac, it can be re-keyed
+// with each password, so it can be pooled, and derives keys without allocating.
+type defaultPRF struct {
+	inner, outer     hash.Hash
+	innerStateReader encoding.BinaryUnmarshaler
+	outerStateReader encoding.BinaryUnmarshaler
+
+	// the states of the inner and outer hashes, once the padded key has been written.
+	innerState, outerState []byte
+
+	pad   [sha256.BlockSize]byte
+	u     [sha256.Size]byte
+	block [4]byte
+}
+
+// prfPool pools the state used by deriveDefaultKey.
+var prfPool = sync.Pool{
+	New: func() interface{} {
+		inner, outer := sha256.New(), sha256.New()
+
+		return &defaultPRF{
+			inner:            inner,
+			outer:            outer,
+			innerStateReader: inner.(encoding.BinaryUnmarshaler),
+			outerStateReader: outer.(encoding.BinaryUnmarshaler),
+		}
+	},
+}
+
+// binaryAppender is implemented by hashes which can append their state to a
+// buffer, without allocating a new one, such as crypto/sha256 since Go 1.24.
+type binaryAppender interface {
+	AppendBinary(b []byte) ([]byte, error)
+}
+
+// returns the state of the hash, reusing the buffer if possible.
+func marshalState(h hash.Hash, buf []byte) []byte {
+	if a, ok := h.(binaryAppender); ok {
+		buf, _ = a.AppendBinary(buf[:0])
+		return buf
+	}
+
+	buf, _ = h.(encoding.BinaryMarshaler).MarshalBinary()
+
+	return buf
+}
+
+// keys the PRF with the password, as described in RFC 2104.
+func (p *defaultPRF) rekey(key []byte) {
+	if len(key) > sha256.BlockSize {
+		// keys longer than the block size are hashed first.
+		p.outer.Reset()
+		p.outer.Write(key)
+		key = p.outer.Sum(p.u[:0])
+	}
+
+	for i := range p.pad {
+		p.pad[i] = 0x36
+	}
+
+	for i, b := range key {
+		p.pad[i] ^= b
+	}
+
+	p.inner.Reset()
+	p.inner.Write(p.pad[:])
+	p.innerState = marshalState(p.inner, p.innerState)
+
+	for i := range p.pad {
+		p.pad[i] ^= 0x36 ^ 0x5c
+	}
+
+	p.outer.Reset()
+	p.outer.Write(p.pad[:])
+	p.outerState = marshalState(p.outer, p.outerState)
+
+	wipe(p.pad[:])
+}
+
+// writes HMAC(key, a || b) to out, which may be a, returning it.
+func (p *defaultPRF) sum(out, a, b []byte) []byte {
+	p.innerStateReader.UnmarshalBinary(p.innerState)
+	p.inner.Write(a)
+	p.inner.Write(b)
+	out = p.inner.Sum(out[:0])
+
+	p.outerStateReader.UnmarshalBinary(p.outerState)
+	p.outer.Write(out)
+
+	return p.outer.Sum(out[:0])
+}
+
+// wipes the key material held by the PRF, and returns it to the pool.
+func putPRF(p *defaultPRF) {
+	wipe(p.innerState)
+	wipe(p.outerState)
+	wipe(p.u[:])
+	prfPool.Put(p)
+}
+
+// derives a key the size of a SHA256 digest, using pbkdf2 with HMAC-SHA256, into the
+// buffer, in the same way as deriveKeyContext. As the key is a single block, and the PRF
+// is pooled, this runs without allocating, making it a fast path for verifying hashes
+// with the default algorithm and key size, which are the most common.
+//
+// Running without allocating requires Go 1.24 or later, where crypto/sha256 can append
+// its state to an existing buffer. Earlier versions allocate each time the PRF is keyed,
+// see marshalState, but still derive the same key.
+func deriveDefaultKey(ctx context.Context, buf *[]byte, pwd, salt []byte, iterCnt int) ([]byte, error) {
+	p := prfPool.Get().(*defaultPRF)
+	defer putPRF(p)
+
+	p.rekey(pwd)
+
+	if cap(*buf) < sha256.Size {
+		*buf = make([]byte, 0, sha256.Size)
+	}
+
+	// T_1 = U_1 ^ U_2 ^ ... ^ U_iterCnt, where
+	// U_1 = PRF(pwd, salt || INT(1)) and U_n = PRF(pwd, U_n-1).
+	p.block = [4]byte{0, 0, 0, 1}
+	t := p.sum((*buf)[:sha256.Size], salt, p.block[:])
+	u := p.u[:]
+	copy(u, t)
+
+	for n := 2; n <= iterCnt; n++ {
+		u = p.sum(u, u, nil)
+
+		for i := range u {
+			t[i] ^= u[i]
+		}
+
+		if n%progressInterval == 0 && n != iterCnt {
+			if err := ctx.Err(); err != nil {
+				return nil, &InterruptedError{Completed: n, Total: iterCnt, Err: err}
+			}
+		}
+	}
+
+	return t, nil
+}
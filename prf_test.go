@@ -0,0 +1,122 @@
+package hasher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestDeriveDefaultKey(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Pwd     []byte
+		Salt    []byte
+		IterCnt int
+	}{
+		{Name: "Default", Pwd: []byte("MyTestPassword"), Salt: []byte("MyTestSaltMySalt"), IterCnt: DefaultIterationCount},
+		{Name: "Single Iteration", Pwd: []byte("MyTestPassword"), Salt: []byte("MyTestSalt"), IterCnt: 1},
+		{Name: "Empty Password", Pwd: []byte{}, Salt: []byte("MyTestSalt"), IterCnt: 2000},
+		{Name: "Block Size Password", Pwd: bytes.Repeat([]byte{'a'}, sha256.BlockSize), Salt: []byte("MyTestSalt"), IterCnt: 10},
+		{Name: "Long Password", Pwd: bytes.Repeat([]byte{'a'}, sha256.BlockSize+1), Salt: []byte("MyTestSalt"), IterCnt: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			buf := getKeyBuffer()
+			defer putKeyBuffer(buf)
+
+			actual, err := deriveDefaultKey(context.Background(), buf, tc.Pwd, tc.Salt, tc.IterCnt)
+			if err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+
+			expected := pbkdf2.Key(tc.Pwd, tc.Salt, tc.IterCnt, sha256.Size, sha256.New)
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("expected '%x' but got '%x'", expected, actual)
+			}
+		})
+	}
+
+	t.Run("Interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		buf := getKeyBuffer()
+		defer putKeyBuffer(buf)
+
+		_, err := deriveDefaultKey(ctx, buf, []byte("MyTestPassword"), []byte("MyTestSalt"), 5000)
+
+		var interrupted *InterruptedError
+		if !errors.As(err, &interrupted) {
+			t.Fatalf("expected an interrupted error, but got '%v'", err)
+		}
+
+		if interrupted.Completed != progressInterval || interrupted.Total != 5000 {
+			t.Errorf("expected %d of 5000 iterations, but got %d of %d", progressInterval, interrupted.Completed, interrupted.Total)
+		}
+	})
+}
+
+func TestDeriveDefaultKeyAllocs(t *testing.T) {
+	if _, ok := sha256.New().(binaryAppender); !ok {
+		t.Skip("crypto/sha256 can't append its state without allocating before Go 1.24")
+	}
+
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random with the race detector enabled")
+	}
+
+	pwd := []byte("MyTestPassword")
+	salt := []byte("MyTestSaltMySalt")
+
+	buf := getKeyBuffer()
+	defer putKeyBuffer(buf)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		deriveDefaultKey(context.Background(), buf, pwd, salt, DefaultIterationCount)
+	})
+	if allocs != 0 {
+		t.Errorf("expected deriveDefaultKey not to allocate, but got %v allocations", allocs)
+	}
+
+	hash, err := Hash(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	allocs = testing.AllocsPerRun(100, func() {
+		Verify(pwd, hash)
+	})
+	if allocs != 0 {
+		t.Errorf("expected Verify not to allocate, but got %v allocations", allocs)
+	}
+}
+
+func BenchmarkDeriveDefaultKey(b *testing.B) {
+	pwd := []byte("MyTestPassword")
+	salt := []byte("MyTestSaltMySalt")
+
+	b.Run("Fast Path", func(b *testing.B) {
+		buf := getKeyBuffer()
+		defer putKeyBuffer(buf)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			deriveDefaultKey(context.Background(), buf, pwd, salt, DefaultIterationCount)
+		}
+	})
+
+	b.Run("General", func(b *testing.B) {
+		buf := getKeyBuffer()
+		defer putKeyBuffer(buf)
+
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			deriveKeyContext(context.Background(), buf, pwd, salt, DefaultIterationCount, sha256.Size, sha256.New, nil)
+		}
+	})
+}
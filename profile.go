@@ -0,0 +1,95 @@
+package hasher
+
+import (
+	"context"
+	"time"
+)
+
+// ProfileReport breaks down the time spent verifying a hash, as returned by Profile.
+type ProfileReport struct {
+	// Header is the time spent parsing the hash's header, and looking up its algorithm.
+	Header time.Duration
+
+	// Salt is the time spent reading the salt and sub-key from the hash.
+	Salt time.Duration
+
+	// Checks is the time spent on the checks which reject a hash before it's derived,
+	// such as its sub-key size, iteration count and FIPS approval.
+	Checks time.Duration
+
+	// Derivation is the time spent deriving the sub-key, including any pre-hash.
+	Derivation time.Duration
+
+	// Total is the time spent verifying the hash, including the stages above, and
+	// comparing the sub-keys.
+	Total time.Duration
+}
+
+// the stages of verification recorded in a ProfileReport.
+const (
+	stageHeader = iota
+	stageSalt
+	stageChecks
+	stageDerivation
+)
+
+// profiler records the time spent in each stage of verification into a report.
+// A nil profiler records nothing, so verification isn't slowed when not profiling.
+type profiler struct {
+	report ProfileReport
+	start  time.Time
+	last   time.Time
+}
+
+// returns a new profiler, which starts timing immediately.
+func newProfiler() *profiler {
+	now := time.Now()
+
+	return &profiler{start: now, last: now}
+}
+
+// records the time since the last stage ended as the given stage.
+func (p *profiler) lap(stage int) {
+	if p == nil {
+		return
+	}
+
+	now := time.Now()
+	d := now.Sub(p.last)
+	p.last = now
+
+	switch stage {
+	case stageHeader:
+		p.report.Header += d
+	case stageSalt:
+		p.report.Salt += d
+	case stageChecks:
+		p.report.Checks += d
+	case stageDerivation:
+		p.report.Derivation += d
+	}
+}
+
+// returns the report, with the total time since the profiler was created.
+func (p *profiler) done() ProfileReport {
+	p.report.Total = time.Since(p.start)
+
+	return p.report
+}
+
+// Profile verifies the password against the hash, in the same way as VerifyWithReason,
+// returning its error, while recording the time spent in each stage of verification,
+// to help tune the hasher's parameters. The derivation should dominate, with parsing
+// and checks taking a negligible fraction of the total.
+//
+// Profiling adds a little overhead to each stage, so is intended for diagnostics. Time
+// spent waiting for a slot, see WithConcurrencyLimit, is not included in the report.
+func (h *hasher) Profile(pwd, hash []byte) (ProfileReport, error) {
+	release, _ := h.acquire(context.Background())
+	defer release()
+
+	p := newProfiler()
+	err := h.verifyWith(context.Background(), pwd, nil, hash, p, nil)
+
+	return p.done(), err
+}
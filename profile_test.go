@@ -0,0 +1,50 @@
+package hasher
+
+import (
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(20000, DefaultSaltSize, DefaultKeySize, DefaultHashKey)
+	hash, _ := h.Hash(pwd)
+
+	report, err := h.Profile(pwd, hash)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	if report.Derivation <= 0 {
+		t.Errorf("expected the derivation to be timed")
+	}
+
+	if sum := report.Header + report.Salt + report.Checks + report.Derivation; sum > report.Total {
+		t.Errorf("expected the stages to total at most '%v' but got '%v'", report.Total, sum)
+	}
+
+	if report.Derivation < report.Header+report.Salt+report.Checks {
+		t.Errorf("expected the derivation to dominate, but got '%+v'", report)
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		report, err := h.Profile([]byte("wrong"), hash)
+		if err != ErrPasswordMismatch {
+			t.Errorf("expected '%v' but got '%v'", ErrPasswordMismatch, err)
+		}
+
+		if report.Derivation <= 0 {
+			t.Errorf("expected the derivation to be timed")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		report, err := h.Profile(pwd, []byte{0x01})
+		if err == nil {
+			t.Errorf("expected an error")
+		}
+
+		if report.Derivation != 0 {
+			t.Errorf("expected the derivation not to be timed, but got '%v'", report.Derivation)
+		}
+	})
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package hasher
+
+// raceEnabled reports whether the race detector is enabled, in which case sync.Pool
+// drops items at random, so pooled code paths allocate.
+const raceEnabled = true
@@ -0,0 +1,83 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxPasswordLength is the default maximum length, in bytes, of
+// passwords read by HashPasswordReader and VerifyPasswordReader.
+const DefaultMaxPasswordLength = 4096
+
+// ErrPasswordTooLong is returned when a password read from an io.Reader
+// exceeds the hasher's maximum password length.
+var ErrPasswordTooLong = errors.New("password exceeds the maximum length")
+
+// readChunkSize is the number of bytes read from an io.Reader at a time.
+const readChunkSize = 512
+
+// HashPasswordReader reads a password from r, until EOF, then hashes it in the same way
+// as Hash, for passwords read from a stream, such as a pipe from a credential helper.
+// The password is read as-is, including any trailing newline, and is zeroed once hashed.
+//
+// At most the hasher's maximum password length is read, see WithMaxPasswordLength, so
+// ErrPasswordTooLong is returned if r has more data. A non-nil error will also be
+// returned if r returns an error, or a salt could not be generated.
+func (h *hasher) HashPasswordReader(r io.Reader) ([]byte, error) {
+	pwd, err := readPassword(r, h.maxPwdLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(pwd)
+
+	return h.Hash(pwd)
+}
+
+// VerifyPasswordReader reads a password from r, until EOF, then verifies it against
+// the hash in the same way as Verify. The password is zeroed once verified.
+//
+// A non-nil error will be returned if r returns an error, or ErrPasswordTooLong if
+// r has more than the hasher's maximum password length, see HashPasswordReader.
+func (h *hasher) VerifyPasswordReader(r io.Reader, hash []byte) (bool, error) {
+	pwd, err := readPassword(r, h.maxPwdLen)
+	if err != nil {
+		return false, err
+	}
+	defer wipe(pwd)
+
+	return h.Verify(pwd, hash), nil
+}
+
+// reads a password of at most maxLen bytes from r, until EOF. Each chunk is
+// read into a buffer which is zeroed, and accumulated using a PasswordBuilder,
+// so no copies of the password are left behind, unlike io.ReadAll.
+func readPassword(r io.Reader, maxLen int) ([]byte, error) {
+	var b PasswordBuilder
+
+	chunk := make([]byte, readChunkSize)
+	defer wipe(chunk)
+
+	// reading one byte more than the max detects passwords which are too long.
+	lr := io.LimitReader(r, int64(maxLen)+1)
+	for {
+		n, err := lr.Read(chunk)
+		b.Write(chunk[:n])
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			b.Wipe()
+			return nil, fmt.Errorf("hasher: failed to read password: %w", err)
+		}
+	}
+
+	if b.Len() > maxLen {
+		b.Wipe()
+		return nil, ErrPasswordTooLong
+	}
+
+	return b.buf, nil
+}
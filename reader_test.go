@@ -0,0 +1,81 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestHashPasswordReader(t *testing.T) {
+	pwd := "MyTestPassword"
+
+	hash, err := defaultHasher.HashPasswordReader(strings.NewReader(pwd))
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !Verify([]byte(pwd), hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	ok, err := defaultHasher.VerifyPasswordReader(iotest.OneByteReader(strings.NewReader(pwd)), hash)
+	if !ok || err != nil {
+		t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		ok, err := defaultHasher.VerifyPasswordReader(strings.NewReader(pwd+"\n"), hash)
+		if ok || err != nil {
+			t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("Read Error", func(t *testing.T) {
+		testErr := errors.New("pipe closed")
+		r := iotest.ErrReader(testErr)
+
+		if _, err := defaultHasher.HashPasswordReader(r); !errors.Is(err, testErr) {
+			t.Errorf("expected '%v' but got '%v'", testErr, err)
+		}
+
+		if ok, err := defaultHasher.VerifyPasswordReader(r, hash); ok || !errors.Is(err, testErr) {
+			t.Errorf("expected (false, '%v') but got (%v, %v)", testErr, ok, err)
+		}
+	})
+}
+
+func TestWithMaxPasswordLength(t *testing.T) {
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMaxPasswordLength(8))
+
+	if _, err := h.HashPasswordReader(strings.NewReader("12345678")); err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	t.Run("Too Long", func(t *testing.T) {
+		if _, err := h.HashPasswordReader(strings.NewReader("123456789")); err != ErrPasswordTooLong {
+			t.Errorf("expected '%v' but got '%v'", ErrPasswordTooLong, err)
+		}
+
+		if _, err := h.VerifyPasswordReader(strings.NewReader("123456789"), nil); err != ErrPasswordTooLong {
+			t.Errorf("expected '%v' but got '%v'", ErrPasswordTooLong, err)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		long := bytes.Repeat([]byte{'a'}, DefaultMaxPasswordLength+1)
+		if _, err := defaultHasher.HashPasswordReader(bytes.NewReader(long)); err != ErrPasswordTooLong {
+			t.Errorf("expected '%v' but got '%v'", ErrPasswordTooLong, err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			_, err := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMaxPasswordLength(n))
+			if err != ErrInvalidMaxPasswordLength {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidMaxPasswordLength, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package hasher
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// HashRecord contains the values stored in a hash, as separate fields, so they can
+// be mapped to normalized columns, such as by an ORM, rather than an opaque blob.
+type HashRecord struct {
+	// Version is the format version of the hash.
+	Version int
+
+	// Algorithm is the name of the algorithm used, such as "sha256".
+	Algorithm string
+
+	// Iterations is the iteration count.
+	Iterations int
+
+	// PreHash is the name of the pre-hash algorithm, or empty if the
+	// password was not pre-hashed, see WithPreHash.
+	PreHash string
+
+	// CreatedAt is the time the hash was created, or the zero time if
+	// it was not recorded, see WithTimestamp.
+	CreatedAt time.Time
+
+	// KeyLengthInHeader determines whether or not the length of the sub-key
+	// is recorded in the hash's header, see WithKeyLengthInHeader.
+	KeyLengthInHeader bool
+
+	// Context determines whether or not the hash was derived with a
+	// context, see WithContext.
+	Context bool
+
+	// OuterHash is the name of the outer hash algorithm, or empty if the
+	// sub-key was not passed through an outer hash, see WithOuterHash.
+	OuterHash string
+
+	// Identity determines whether or not the hash was derived with an
+	// identity, see HashWithIdentity.
+	Identity bool
+
+	Salt   []byte
+	SubKey []byte
+}
+
+// ToRecord returns a HashRecord containing the values stored in the given hash. The
+// record can be converted back to the same hash using FromRecord, unless the hash
+// declares its key length, and has trailing bytes, which are not included.
+//
+// A non-nil error will be returned if the hash is in an invalid format, or uses an
+// unsupported format version or algorithm.
+func ToRecord(hash []byte) (HashRecord, error) {
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return HashRecord{}, err
+	}
+
+	algName, ok := algNames[hdr.hashKey]
+	if !ok {
+		return HashRecord{}, ErrUnsupportedHashKey
+	}
+
+	r := HashRecord{
+		Version:           hdr.version,
+		Algorithm:         algName,
+		Iterations:        hdr.iterCnt,
+		KeyLengthInHeader: hdr.flags&flagKeyLen != 0,
+		Context:           hdr.flags&flagContext != 0,
+		Identity:          hdr.flags&flagIdentity != 0,
+		Salt:              append([]byte{}, hash[hdr.size:hdr.size+hdr.saltLen]...),
+		SubKey:            append([]byte{}, hdr.subKey(hash)...),
+	}
+
+	if hdr.flags&flagPreHash != 0 {
+		if r.PreHash, ok = algNames[hdr.preHash]; !ok {
+			return HashRecord{}, ErrUnsupportedHashKey
+		}
+	}
+
+	if hdr.flags&flagTimestamp != 0 {
+		r.CreatedAt = time.Unix(hdr.created, 0)
+	}
+
+	if hdr.flags&flagOuterHash != 0 {
+		if r.OuterHash, ok = algNames[hdr.outerHash]; !ok {
+			return HashRecord{}, ErrUnsupportedHashKey
+		}
+	}
+
+	return r, nil
+}
+
+// FromRecord returns the hash containing the values in the given record, which
+// is the inverse of ToRecord.
+//
+// A non-nil error will be returned if any of the values are invalid, including
+// values which can't be stored in a version 1 hash, such as a pre-hash.
+func FromRecord(r HashRecord) ([]byte, error) {
+	hdr, err := recordHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, hdr.len()+len(r.Salt)+len(r.SubKey))
+	n := writeHeader(out, hdr)
+
+	copy(out[n:], r.Salt)
+	copy(out[n+len(r.Salt):], r.SubKey)
+
+	return out, nil
+}
+
+// returns the header of the hash containing the values in the given record, returning
+// a non-nil error if any of the values are invalid, as described by FromRecord.
+func recordHeader(r HashRecord) (header, error) {
+	if headerLen(r.Version) == 0 {
+		return header{}, ErrUnsupportedVersion
+	}
+
+	hashKey, ok := lookupAlgName(r.Algorithm)
+	if !ok {
+		return header{}, ErrUnsupportedHashKey
+	}
+
+	if r.Iterations < 0 || uint64(r.Iterations) > math.MaxUint32 {
+		return header{}, ErrInvalidIterationCount
+	}
+
+	if len(r.Salt) == 0 {
+		return header{}, ErrEmptySalt
+	}
+
+	hdr := header{
+		version: r.Version,
+		hashKey: hashKey,
+		iterCnt: r.Iterations,
+		saltLen: len(r.Salt),
+	}
+
+	if r.PreHash != "" {
+		hdr.flags |= flagPreHash
+		if hdr.preHash, ok = lookupAlgName(r.PreHash); !ok {
+			return header{}, ErrUnsupportedHashKey
+		}
+	}
+
+	if !r.CreatedAt.IsZero() {
+		hdr.flags |= flagTimestamp
+		hdr.created = r.CreatedAt.Unix()
+	}
+
+	if r.KeyLengthInHeader {
+		hdr.flags |= flagKeyLen
+		hdr.keyLen = len(r.SubKey)
+	}
+
+	if r.Context {
+		hdr.flags |= flagContext
+	}
+
+	if r.Identity {
+		hdr.flags |= flagIdentity
+	}
+
+	if r.OuterHash != "" {
+		hdr.flags |= flagOuterHash
+		if hdr.outerHash, ok = lookupAlgName(r.OuterHash); !ok {
+			return header{}, ErrUnsupportedHashKey
+		}
+	}
+
+	if hdr.version == 1 && hdr.flags != 0 {
+		// version 1 headers have no flags, to record optional values.
+		return header{}, ErrInvalidFormat
+	}
+
+	return hdr, nil
+}
+
+// VerifyRecord verifies the password against the hash containing the values in the given
+// record, in the same way as Verify, without converting the record back to a hash first,
+// for records loaded from separate columns, such as by an ORM. The record's values are
+// validated in the same way as FromRecord, before the sub-key is derived.
+//
+// Will return false, and a nil error, if the password doesn't match. A non-nil error will
+// be returned if the record is invalid, or couldn't be verified, in the same way as
+// VerifyWithReason, such as ErrHashTooWeak.
+func (h *hasher) VerifyRecord(pwd []byte, r HashRecord) (bool, error) {
+	hdr, err := recordHeader(r)
+	if err != nil {
+		return false, err
+	}
+
+	if hdr.iterCnt < 1 {
+		return false, ErrInvalidIterationCount
+	}
+
+	if len(r.SubKey) == 0 {
+		return false, ErrInvalidFormat
+	}
+
+	if err := h.checkAlgorithm(hdr.hashKey); err != nil {
+		return false, err
+	}
+
+	release, _ := h.acquire(context.Background())
+	defer release()
+
+	err = h.verifyComponents(context.Background(), pwd, nil, hdr, r.Salt, r.SubKey, nil, nil)
+	if err == ErrPasswordMismatch {
+		return false, nil
+	}
+
+	return err == nil, err
+}
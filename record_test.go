@@ -0,0 +1,147 @@
+package hasher
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	hashers := map[string]Hasher{}
+	hashers["Default"] = defaultHasher
+	hashers["All Options"], _ = New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, HashSHA512,
+		WithPreHash(HashSHA256), WithTimestamp(true), WithKeyLengthInHeader(true))
+
+	for name, h := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, _ := h.Hash(pwd)
+
+			r, err := ToRecord(hash)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+				return
+			}
+
+			if r.Algorithm != algNames[h.Algorithm()] || r.Iterations != DefaultIterationCount {
+				t.Errorf("expected '%s' with %d iterations but got '%s' with %d",
+					algNames[h.Algorithm()], DefaultIterationCount, r.Algorithm, r.Iterations)
+			}
+
+			out, err := FromRecord(r)
+			if err != nil {
+				t.Errorf("didn't expect to get an error: %v", err)
+				return
+			}
+
+			if !bytes.Equal(out, hash) {
+				t.Errorf("expected '%x' but got '%x'", hash, out)
+			}
+		})
+	}
+
+	t.Run("Version 1", func(t *testing.T) {
+		r := HashRecord{
+			Version:    1,
+			Algorithm:  "sha256",
+			Iterations: DefaultIterationCount,
+			Salt:       make([]byte, DefaultSaltSize/8),
+		}
+		r.SubKey, _ = DeriveKey(pwd, r.Salt, r.Iterations, DefaultKeySize/8, HashSHA256)
+
+		hash, err := FromRecord(r)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+			return
+		}
+
+		if !Verify(pwd, hash) {
+			t.Errorf("expected hash to be valid")
+		}
+
+		r.CreatedAt = time.Now()
+		if _, err := FromRecord(r); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+
+	t.Run("Invalid Record", func(t *testing.T) {
+		testCases := []struct {
+			Name     string
+			Record   HashRecord
+			Expected error
+		}{
+			{Name: "Version", Record: HashRecord{Version: 237, Algorithm: "sha256"}, Expected: ErrUnsupportedVersion},
+			{Name: "Algorithm", Record: HashRecord{Version: 2, Algorithm: "md5"}, Expected: ErrUnsupportedHashKey},
+			{Name: "Pre-Hash", Record: HashRecord{Version: 2, Algorithm: "sha256", Salt: []byte("salt"), PreHash: "md5"}, Expected: ErrUnsupportedHashKey},
+			{Name: "Iterations", Record: HashRecord{Version: 2, Algorithm: "sha256", Iterations: -1}, Expected: ErrInvalidIterationCount},
+			{Name: "Empty Salt", Record: HashRecord{Version: 2, Algorithm: "sha256"}, Expected: ErrEmptySalt},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				if _, err := FromRecord(tc.Record); err != tc.Expected {
+					t.Errorf("expected '%v' but got '%v'", tc.Expected, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		if _, err := ToRecord([]byte{0x23}); err != ErrInvalidFormat {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidFormat, err)
+		}
+	})
+}
+
+func TestVerifyRecord(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithPreHash(HashSHA512), WithOuterHash(HashSHA512))
+	hash, _ := h.Hash(pwd)
+
+	r, err := ToRecord(hash)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	ok, err := h.VerifyRecord(pwd, r)
+	if !ok || err != nil {
+		t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+	}
+
+	ok, err = h.VerifyRecord([]byte("NotMyPassword"), r)
+	if ok || err != nil {
+		t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+	}
+
+	t.Run("Invalid Record", func(t *testing.T) {
+		testCases := []struct {
+			Name     string
+			Modify   func(r *HashRecord)
+			Expected error
+		}{
+			{"Unsupported Version", func(r *HashRecord) { r.Version = 9 }, ErrUnsupportedVersion},
+			{"Unsupported Algorithm", func(r *HashRecord) { r.Algorithm = "md5" }, ErrUnsupportedHashKey},
+			{"Zero Iterations", func(r *HashRecord) { r.Iterations = 0 }, ErrInvalidIterationCount},
+			{"Empty Salt", func(r *HashRecord) { r.Salt = nil }, ErrEmptySalt},
+			{"Empty Sub-Key", func(r *HashRecord) { r.SubKey = nil }, ErrInvalidFormat},
+			{"Short Salt", func(r *HashRecord) { r.Salt = r.Salt[:8] }, ErrHashTooWeak},
+			{"Short Sub-Key", func(r *HashRecord) { r.SubKey = r.SubKey[:16] }, ErrHashTooWeak},
+			{"Context", func(r *HashRecord) { r.Context = true }, ErrContextMismatch},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Name, func(t *testing.T) {
+				r, _ := ToRecord(hash)
+				tc.Modify(&r)
+
+				ok, err := h.VerifyRecord(pwd, r)
+				if ok || err != tc.Expected {
+					t.Errorf("expected (false, '%v') but got (%v, '%v')", tc.Expected, ok, err)
+				}
+			})
+		}
+	})
+}
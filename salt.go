@@ -0,0 +1,194 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"golang.org/x/crypto/chacha20"
+)
+
+// Errors returned by Hash when a salt can't be generated.
+var (
+	// ErrLowSaltEntropy is returned when every salt generated has an implausibly
+	// low entropy, see WithMinSaltEntropy.
+	ErrLowSaltEntropy = errors.New("generated salts have implausibly low entropy, the random number generator may be broken")
+
+	// ErrSaltsExhausted is returned when a SaltSource returned by SequenceSalts
+	// has already returned each of its salts.
+	ErrSaltsExhausted = errors.New("every salt in the sequence has been used")
+)
+
+// saltAttempts is the number of salts generated by Hash, before returning ErrLowSaltEntropy,
+// when each has less than the minimum entropy, see WithMinSaltEntropy.
+const saltAttempts = 3
+
+// SaltSource is used by a Hasher to generate salts. By default, salts are
+// generated using crypto/rand, however, a SaltSource can be used to generate
+// them elsewhere, such as a hardware security module.
+type SaltSource interface {
+	// Generate returns a salt of n bytes. A non-nil error should be
+	// returned if a salt could not be generated.
+	Generate(n int) ([]byte, error)
+}
+
+// randSource is the default SaltSource, which is backed by crypto/rand.
+type randSource struct{}
+
+// Generate returns n bytes read from crypto/rand.
+func (randSource) Generate(n int) ([]byte, error) {
+	salt := make([]byte, n)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+// seededSource is a SaltSource which generates a reproducible sequence of salts
+// from a seed, using the ChaCha20 keystream, see WithDeterministicSalt.
+type seededSource struct {
+	mu     sync.Mutex
+	stream *chacha20.Cipher
+}
+
+// returns a new seededSource, keyed by the SHA256 digest of the seed.
+func newSeededSource(seed []byte) *seededSource {
+	key := sha256.Sum256(seed)
+	nonce := make([]byte, chacha20.NonceSize)
+
+	// this can't fail, as the key and nonce are always the right size.
+	stream, _ := chacha20.NewUnauthenticatedCipher(key[:], nonce)
+
+	return &seededSource{stream: stream}
+}
+
+// Generate returns the next n bytes of the keystream.
+func (s *seededSource) Generate(n int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	salt := make([]byte, n)
+	s.stream.XORKeyStream(salt, salt)
+
+	return salt, nil
+}
+
+// SequenceSalts returns a SaltSource which returns each of the given salts in turn, then
+// ErrSaltsExhausted, for use with WithSaltSource in golden tests, which assert the exact
+// bytes produced by Hash, without replacing any package globals. Each salt must be the
+// hasher's salt size, otherwise Hash returns an error. The source is safe for concurrent
+// use, though the order concurrent calls to Hash receive salts in is unspecified.
+//
+// This is insecure, and must only be used for testing. Anyone who knows the salts can
+// precompute hashes for them, so never use it in production.
+func SequenceSalts(salts ...[]byte) SaltSource {
+	s := &saltSequence{salts: make([][]byte, len(salts))}
+	for i, salt := range salts {
+		s.salts[i] = append([]byte{}, salt...)
+	}
+
+	return s
+}
+
+// saltSequence is a SaltSource which returns each of its salts in turn, see SequenceSalts.
+type saltSequence struct {
+	mu    sync.Mutex
+	salts [][]byte
+	next  int
+}
+
+// Generate returns a copy of the next salt in the sequence, or ErrSaltsExhausted.
+func (s *saltSequence) Generate(n int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == len(s.salts) {
+		return nil, ErrSaltsExhausted
+	}
+
+	salt := append([]byte{}, s.salts[s.next]...)
+	s.next++
+
+	return salt, nil
+}
+
+// generates a salt with the hasher's salt source, in the same way as readSalt, and if
+// the hasher has a minimum salt entropy, regenerates it while its entropy is too low,
+// returning ErrLowSaltEntropy after saltAttempts salts.
+func (h *hasher) generateSalt(buf *[]byte) ([]byte, error) {
+	for i := 1; ; i++ {
+		salt, err := h.readSalt(buf)
+		if err != nil || h.minEntropy == 0 || saltEntropy(salt) >= h.minEntropy {
+			return salt, err
+		}
+
+		if i == saltAttempts {
+			return nil, ErrLowSaltEntropy
+		}
+	}
+}
+
+// maxSaltEntropyCeiling is the largest minimum salt entropy accepted for any salt
+// size, see maxMinSaltEntropy.
+const maxSaltEntropyCeiling = 6
+
+// returns the largest minimum entropy, in bits per byte, which salts of the given size,
+// in bytes, from a healthy RNG reliably reach. The estimate can't exceed log2 of the
+// size, or 8, and falls short of both by chance, by most for a size near 256, where
+// some byte values occur twice and others not at all. A margin of a bit per byte, and
+// the ceiling, keep the chance of a random salt falling short below 1 in 10,000.
+func maxMinSaltEntropy(saltSize int) float64 {
+	return math.Min(math.Log2(float64(saltSize))-1, maxSaltEntropyCeiling)
+}
+
+// returns the Shannon entropy of the salt's bytes, in bits per byte, estimated
+// from the frequency of each byte value in the salt.
+func saltEntropy(salt []byte) float64 {
+	var counts [256]int
+	for _, b := range salt {
+		counts[b]++
+	}
+
+	var e float64
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(len(salt))
+			e -= p * math.Log2(p)
+		}
+	}
+
+	return e
+}
+
+// reads a salt from the hasher's salt source, ensuring it is the right size. If
+// the default source is used, the salt is read into buf, growing it if it's too small,
+// as it's only used while hashing, otherwise, the source allocates the salt.
+func (h *hasher) readSalt(buf *[]byte) ([]byte, error) {
+	if _, ok := h.saltSource.(randSource); ok {
+		if cap(*buf) < h.saltSize {
+			*buf = make([]byte, 0, h.saltSize)
+		}
+
+		salt := (*buf)[:h.saltSize]
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("hasher: failed to generate salt: %w", err)
+		}
+
+		return salt, nil
+	}
+
+	salt, err := h.saltSource.Generate(h.saltSize)
+	if err != nil {
+		return nil, fmt.Errorf("hasher: failed to generate salt: %w", err)
+	}
+
+	if len(salt) != h.saltSize {
+		return nil, fmt.Errorf("hasher: salt source returned %d bytes, expected %d", len(salt), h.saltSize)
+	}
+
+	return salt, nil
+}
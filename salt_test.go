@@ -0,0 +1,261 @@
+package hasher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+)
+
+// fixedSource is a SaltSource which returns a fixed salt, or error.
+type fixedSource struct {
+	salt []byte
+	err  error
+}
+
+func (s fixedSource) Generate(n int) ([]byte, error) {
+	return s.salt, s.err
+}
+
+func TestWithSaltSource(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	salt := bytes.Repeat([]byte{0x42}, DefaultSaltSize/8)
+
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithSaltSource(fixedSource{salt: salt}))
+
+	hash, err := hasher.Hash(pwd)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+		return
+	}
+
+	hdr, _ := scanHeader(hash)
+	if !bytes.Equal(hash[hdr.size:hdr.size+hdr.saltLen], salt) {
+		t.Errorf("expected the salt to be generated by the salt source")
+	}
+
+	if !hasher.Verify(pwd, hash) {
+		t.Errorf("expected hash to be valid")
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		testErr := errors.New("hsm unavailable")
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithSaltSource(fixedSource{err: testErr}))
+
+		hash, err := hasher.Hash(pwd)
+		if !errors.Is(err, testErr) {
+			t.Errorf("expected '%v' but got '%v'", testErr, err)
+		}
+
+		if hash != nil {
+			t.Errorf("expected a nil hash")
+		}
+	})
+
+	t.Run("Wrong Size", func(t *testing.T) {
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithSaltSource(fixedSource{salt: []byte{0x42}}))
+
+		if _, err := hasher.Hash(pwd); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+}
+
+func TestWithDeterministicSalt(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	salts := func(seed string) [][]byte {
+		hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithDeterministicSalt([]byte(seed)))
+
+		var salts [][]byte
+		for i := 0; i < 3; i++ {
+			hash, err := hasher.Hash(pwd)
+			if err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+
+			if !hasher.Verify(pwd, hash) {
+				t.Errorf("expected hash to be valid")
+			}
+
+			hdr, _ := scanHeader(hash)
+			salts = append(salts, hash[hdr.size:hdr.size+hdr.saltLen])
+		}
+
+		return salts
+	}
+
+	a, b, other := salts("user-1"), salts("user-1"), salts("user-2")
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			t.Errorf("expected salt %d to be the same for the same seed", i)
+		}
+
+		if bytes.Equal(a[i], other[i]) {
+			t.Errorf("expected salt %d to differ for a different seed", i)
+		}
+	}
+
+	if bytes.Equal(a[0], a[1]) {
+		t.Errorf("expected each salt in the sequence to be distinct")
+	}
+}
+
+func TestSequenceSalts(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	first, second := make([]byte, 16), make([]byte, 16)
+	for i := range first {
+		first[i], second[i] = byte(i), byte(16+i)
+	}
+
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithSaltSource(SequenceSalts(first, second)))
+
+	// the magic, version 2, no flags, SHA256, 1000 iterations and a 16-byte salt,
+	// followed by the salt, and the sub-key.
+	golden := []string{
+		"ad020000000001000003e800000010" + "000102030405060708090a0b0c0d0e0f" +
+			"66d179e09e60a4990223e5b9aae76a07b2444c0d7b3f61416a560ee39e4dd713",
+		"ad020000000001000003e800000010" + "101112131415161718191a1b1c1d1e1f" +
+			"fc0f11228fc132282a8462d9fab563c9c53c8ccded26b619bd3ee168c18fd848",
+	}
+
+	for i, expected := range golden {
+		hash, err := h.Hash(pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		if actual := hex.EncodeToString(hash); actual != expected {
+			t.Errorf("hash %d: expected '%s' but got '%s'", i, expected, actual)
+		}
+	}
+
+	t.Run("Exhausted", func(t *testing.T) {
+		hash, err := h.Hash(pwd)
+		if !errors.Is(err, ErrSaltsExhausted) {
+			t.Errorf("expected '%v' but got '%v'", ErrSaltsExhausted, err)
+		}
+
+		if hash != nil {
+			t.Errorf("expected a nil hash")
+		}
+	})
+
+	t.Run("Copies Salts", func(t *testing.T) {
+		salt := append([]byte{}, first...)
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithSaltSource(SequenceSalts(salt)))
+		salt[0] = 0xFF
+
+		hash, _ := h.Hash(pwd)
+		if actual := hex.EncodeToString(hash); actual != golden[0] {
+			t.Errorf("expected '%s' but got '%s'", golden[0], actual)
+		}
+	})
+}
+
+func TestWithMinSaltEntropy(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	stuck := bytes.Repeat([]byte{0x42}, DefaultSaltSize/8)
+
+	random := make([]byte, DefaultSaltSize/8)
+	for i := range random {
+		random[i] = byte(i * 17)
+	}
+
+	t.Run("Regenerated", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithSaltSource(SequenceSalts(stuck, random)), WithMinSaltEntropy(3))
+
+		hash, err := h.Hash(pwd)
+		if err != nil {
+			t.Fatalf("didn't expect to get an error: %v", err)
+		}
+
+		hdr, _ := scanHeader(hash)
+		if !bytes.Equal(hash[hdr.size:hdr.size+hdr.saltLen], random) {
+			t.Errorf("expected the low entropy salt to be replaced")
+		}
+
+		// both salts were generated, so the sequence is exhausted.
+		if _, err := h.Hash(pwd); !errors.Is(err, ErrSaltsExhausted) {
+			t.Errorf("expected '%v' but got '%v'", ErrSaltsExhausted, err)
+		}
+	})
+
+	t.Run("Broken", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+			WithSaltSource(SequenceSalts(stuck, stuck, stuck, random)), WithMinSaltEntropy(3))
+
+		if _, err := h.Hash(pwd); err != ErrLowSaltEntropy {
+			t.Errorf("expected '%v' but got '%v'", ErrLowSaltEntropy, err)
+		}
+
+		// only saltAttempts salts were generated, so the next hash gets the random one.
+		if _, err := h.Hash(pwd); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithMinSaltEntropy(3))
+
+		for i := 0; i < 100; i++ {
+			if _, err := h.Hash(pwd); err != nil {
+				t.Fatalf("didn't expect to get an error: %v", err)
+			}
+		}
+	})
+
+	t.Run("Maximum", func(t *testing.T) {
+		// salts from a healthy RNG reach the largest minimum accepted for their size.
+		for _, saltSize := range []int{4, 16, 32, 128, 256, 512} {
+			bits := maxMinSaltEntropy(saltSize)
+			h, err := New(1, saltSize*8, DefaultKeySize, DefaultHashKey, WithMinSaltEntropy(bits))
+			if err != nil {
+				t.Fatalf("%d: didn't expect to get an error: %v", saltSize, err)
+			}
+
+			for i := 0; i < 2000; i++ {
+				if _, err := h.Hash(pwd); err != nil {
+					t.Fatalf("%d: didn't expect to get an error: %v", saltSize, err)
+				}
+			}
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		testCases := []struct {
+			SaltSize int
+			Bits     float64
+		}{
+			{DefaultSaltSize, -1},
+			{DefaultSaltSize, 3.01},
+			{DefaultSaltSize, 4},
+			{DefaultSaltSize, math.NaN()},
+			{4096, 6.01},
+			{4096, 8.5},
+			{16, 0.5},
+		}
+
+		for _, tc := range testCases {
+			_, err := New(DefaultIterationCount, tc.SaltSize, DefaultKeySize, DefaultHashKey, WithMinSaltEntropy(tc.Bits))
+			if err != ErrInvalidSaltEntropy {
+				t.Errorf("%d, %v: expected '%v' but got '%v'", tc.SaltSize, tc.Bits, ErrInvalidSaltEntropy, err)
+			}
+		}
+	})
+
+	t.Run("Entropy", func(t *testing.T) {
+		if e := saltEntropy(random); e != 4 {
+			t.Errorf("expected 4 bits per byte but got %v", e)
+		}
+
+		if e := saltEntropy(stuck); e != 0 {
+			t.Errorf("expected 0 bits per byte but got %v", e)
+		}
+	})
+}
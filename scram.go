@@ -0,0 +1,88 @@
+package hasher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrInvalidSCRAM is returned when a SCRAM verifier is in an invalid format.
+var ErrInvalidSCRAM = errors.New("string is not a valid scram-sha-256 verifier")
+
+// scramPrefix is the scheme of SCRAM-SHA-256 verifiers, as stored by PostgreSQL.
+const scramPrefix = "SCRAM-SHA-256$"
+
+// VerifySCRAMSHA256 verifies the password against a SCRAM-SHA-256 verifier, as stored
+// by PostgreSQL in pg_authid, returning a flag which determines whether or not the
+// password matches. This is useful when migrating away from PostgreSQL-managed auth.
+//
+// The verifier must be in the format "SCRAM-SHA-256$<iter>:<salt>$<StoredKey>:<ServerKey>",
+// where the salt and keys are encoded using standard base64. The salted password is
+// derived using PBKDF2-HMAC-SHA-256, from which both keys are computed, see RFC 5802.
+//
+// PostgreSQL normalizes passwords with SASLprep before hashing them, which is not
+// applied, so non-ASCII passwords must be normalized by the caller.
+//
+// A non-nil error will be returned if the verifier is in an invalid format.
+func VerifySCRAMSHA256(pwd []byte, verifier string) (bool, error) {
+	if !strings.HasPrefix(verifier, scramPrefix) {
+		return false, ErrInvalidSCRAM
+	}
+
+	fields := strings.Split(strings.TrimPrefix(verifier, scramPrefix), "$")
+	if len(fields) != 2 {
+		return false, ErrInvalidSCRAM
+	}
+
+	params := strings.Split(fields[0], ":")
+	keys := strings.Split(fields[1], ":")
+	if len(params) != 2 || len(keys) != 2 {
+		return false, ErrInvalidSCRAM
+	}
+
+	iterCnt, err := parseIterations(params[0])
+	if err != nil {
+		return false, ErrInvalidSCRAM
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(params[1])
+	if err != nil {
+		return false, ErrInvalidSCRAM
+	}
+
+	storedKey, err := base64.StdEncoding.DecodeString(keys[0])
+	if err != nil || len(storedKey) != sha256.Size {
+		return false, ErrInvalidSCRAM
+	}
+
+	serverKey, err := base64.StdEncoding.DecodeString(keys[1])
+	if err != nil || len(serverKey) != sha256.Size {
+		return false, ErrInvalidSCRAM
+	}
+
+	salted := pbkdf2.Key(pwd, salt, iterCnt, sha256.Size, sha256.New)
+
+	// StoredKey = H(HMAC(SaltedPassword, "Client Key")),
+	// ServerKey = HMAC(SaltedPassword, "Server Key").
+	clientKey := scramHMAC(salted, "Client Key")
+	actualStored := sha256.Sum256(clientKey)
+	actualServer := scramHMAC(salted, "Server Key")
+
+	ok := subtle.ConstantTimeCompare(actualStored[:], storedKey) &
+		subtle.ConstantTimeCompare(actualServer, serverKey)
+
+	return ok == 1, nil
+}
+
+// returns the HMAC-SHA-256 of the message, using the given key.
+func scramHMAC(key []byte, msg string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package hasher
+
+import "testing"
+
+func TestVerifySCRAMSHA256(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+
+	// built following PostgreSQL's scram_build_secret, with a salt of bytes 0 to 15.
+	verifier := "SCRAM-SHA-256$4096:AAECAwQFBgcICQoLDA0ODw==$ltrF8/YpXjeINIAuDjx/4PkFVMzp5hvoiU35991wz4w=:/YIwCAecxkyFU8eOC/rsCrOSneRlf8lBcT2i8SXn9RQ="
+
+	ok, err := VerifySCRAMSHA256(pwd, verifier)
+	if err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	if !ok {
+		t.Errorf("expected verifier to be valid")
+	}
+
+	t.Run("PostgreSQL", func(t *testing.T) {
+		// the secret assigned to regress_passwd_sha_len0 by PostgreSQL's password
+		// regression tests, src/test/regress/sql/password.sql.
+		stored := "SCRAM-SHA-256$4096:A6xHKoH/494E941doaPOYg==$Ky+A30sewHIH3VHQLRN9vYsuzlgNyGNKCh37dy96Rqw=:COPdlNiIkrsacU5QoxydEuOH6e/KfiipeETb/bPw8ZI="
+
+		ok, err := VerifySCRAMSHA256([]byte("password"), stored)
+		if !ok || err != nil {
+			t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+		}
+
+		ok, err = VerifySCRAMSHA256([]byte("password!"), stored)
+		if ok || err != nil {
+			t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		ok, err := VerifySCRAMSHA256([]byte("NotMyPassword"), verifier)
+		if err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		if ok {
+			t.Errorf("expected verifier to be invalid")
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		verifiers := map[string]string{
+			"Scheme":     "md5d15d0d4a2d4e38bde6d4a0a83b7e4ea0",
+			"Fields":     "SCRAM-SHA-256$4096:AAECAwQFBgcICQoLDA0ODw==",
+			"Iterations": "SCRAM-SHA-256$0:AAECAwQFBgcICQoLDA0ODw==$ltrF8/YpXjeINIAuDjx/4PkFVMzp5hvoiU35991wz4w=:/YIwCAecxkyFU8eOC/rsCrOSneRlf8lBcT2i8SXn9RQ=",
+			"Salt":       "SCRAM-SHA-256$4096:!!!$ltrF8/YpXjeINIAuDjx/4PkFVMzp5hvoiU35991wz4w=:/YIwCAecxkyFU8eOC/rsCrOSneRlf8lBcT2i8SXn9RQ=",
+			"Key Length": "SCRAM-SHA-256$4096:AAECAwQFBgcICQoLDA0ODw==$AAAA:/YIwCAecxkyFU8eOC/rsCrOSneRlf8lBcT2i8SXn9RQ=",
+		}
+
+		for name, v := range verifiers {
+			t.Run(name, func(t *testing.T) {
+				if _, err := VerifySCRAMSHA256(pwd, v); err != ErrInvalidSCRAM {
+					t.Errorf("expected '%v' but got '%v'", ErrInvalidSCRAM, err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package hasher
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCrypt is returned when a string is not in the SHA-crypt format.
+var ErrInvalidCrypt = errors.New("string is not in the sha-crypt format")
+
+const (
+	// shaCryptDefaultRounds is the number of rounds used when an entry doesn't specify them.
+	shaCryptDefaultRounds = 5000
+
+	// shaCryptMinRounds and shaCryptMaxRounds are the bounds rounds are clamped to.
+	shaCryptMinRounds = 1000
+	shaCryptMaxRounds = 999999999
+
+	// shaCryptMaxSalt is the maximum number of salt characters used, any more are ignored.
+	shaCryptMaxSalt = 16
+
+	// shaCryptAlphabet is the alphabet used to encode SHA-crypt checksums.
+	shaCryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// shaCryptScheme is a variant of SHA-crypt, identified by its crypt prefix.
+type shaCryptScheme struct {
+	hashFunc func() hash.Hash
+
+	// order is the order the digest's bytes are encoded in, in groups of three.
+	order []int
+}
+
+// shaCryptSchemes maps the supported crypt ids to their schemes.
+var shaCryptSchemes = map[string]shaCryptScheme{
+	"5": {
+		hashFunc: sha256.New,
+		order: []int{
+			0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14,
+			15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29,
+			31, 30,
+		},
+	},
+	"6": {
+		hashFunc: sha512.New,
+		order: []int{
+			0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4,
+			47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+			31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35,
+			15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19,
+			62, 20, 41, 63,
+		},
+	},
+}
+
+// VerifyCrypt verifies the password against a SHA-crypt entry, as found in /etc/shadow
+// on Linux systems, returning a flag which determines whether or not the password matches
+// the hash. SHA-crypt is not pbkdf2, but is supported to ease migrations of system users.
+//
+// The entry must be in the format "$<id>$[rounds=<rounds>$]<salt>$<checksum>", where the
+// id is "5" for SHA256-crypt, or "6" for SHA512-crypt, as described by Ulrich Drepper's
+// specification. As with glibc, rounds are clamped between 1000 and 999999999, and only
+// the first 16 characters of the salt are used.
+//
+// A non-nil error will be returned if the entry is not in the SHA-crypt format, or
+// ErrUnsupportedScheme if the id is not recognised.
+func VerifyCrypt(pwd []byte, entry string) (bool, error) {
+	// the leading '$' results in an empty first field.
+	fields := strings.Split(entry, "$")
+	if len(fields) < 2 || fields[0] != "" {
+		return false, ErrInvalidCrypt
+	}
+
+	scheme, ok := shaCryptSchemes[fields[1]]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnsupportedScheme, fields[1])
+	}
+
+	rounds := shaCryptDefaultRounds
+	if len(fields) == 5 && strings.HasPrefix(fields[2], "rounds=") {
+		n, err := strconv.Atoi(strings.TrimPrefix(fields[2], "rounds="))
+		if err != nil || n < 0 {
+			return false, ErrInvalidCrypt
+		}
+
+		rounds = clampRounds(n)
+		fields = append(fields[:2], fields[3:]...)
+	}
+
+	if len(fields) != 4 {
+		return false, ErrInvalidCrypt
+	}
+
+	salt, checksum := fields[2], fields[3]
+	if len(salt) > shaCryptMaxSalt {
+		salt = salt[:shaCryptMaxSalt]
+	}
+
+	digest := shaCrypt(pwd, []byte(salt), rounds, scheme.hashFunc)
+	actual := encodeShaCrypt(digest, scheme.order)
+	if len(checksum) != len(actual) {
+		return false, ErrInvalidCrypt
+	}
+
+	return ConstantTimeEqual([]byte(actual), []byte(checksum)), nil
+}
+
+// returns the number of rounds, clamped to the range permitted by SHA-crypt.
+func clampRounds(n int) int {
+	if n < shaCryptMinRounds {
+		return shaCryptMinRounds
+	}
+
+	if n > shaCryptMaxRounds {
+		return shaCryptMaxRounds
+	}
+
+	return n
+}
+
+// derives the SHA-crypt digest of the password, with the given salt and rounds.
+func shaCrypt(pwd, salt []byte, rounds int, hashFunc func() hash.Hash) []byte {
+	h := hashFunc()
+	size := h.Size()
+
+	// B = H(pwd || salt || pwd)
+	h.Write(pwd)
+	h.Write(salt)
+	h.Write(pwd)
+	b := h.Sum(nil)
+
+	// A = H(pwd || salt || B repeated to the length of pwd || [B or pwd for each bit of len(pwd)])
+	h.Reset()
+	h.Write(pwd)
+	h.Write(salt)
+	writeRepeated(h, b, len(pwd))
+
+	for n := len(pwd); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(pwd)
+		}
+	}
+
+	a := h.Sum(nil)
+
+	// P = H(pwd repeated len(pwd) times), repeated to the length of pwd.
+	h.Reset()
+	for i := 0; i < len(pwd); i++ {
+		h.Write(pwd)
+	}
+
+	p := repeat(h.Sum(nil), len(pwd))
+
+	// S = H(salt repeated 16 + A[0] times), repeated to the length of salt.
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(salt)
+	}
+
+	s := repeat(h.Sum(nil), len(salt))
+
+	c := a
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+
+		if i%3 != 0 {
+			h.Write(s)
+		}
+
+		if i%7 != 0 {
+			h.Write(p)
+		}
+
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+
+		c = h.Sum(c[:0])
+	}
+
+	return c[:size]
+}
+
+// writes the data to the hash, repeated until n bytes have been written.
+func writeRepeated(h hash.Hash, data []byte, n int) {
+	for ; n > len(data); n -= len(data) {
+		h.Write(data)
+	}
+
+	h.Write(data[:n])
+}
+
+// returns the data repeated until it's n bytes long.
+func repeat(data []byte, n int) []byte {
+	out := make([]byte, n)
+	for i := 0; i < n; i += len(data) {
+		copy(out[i:], data)
+	}
+
+	return out
+}
+
+// encodes the digest using SHA-crypt's base64 variant, which encodes groups of three
+// bytes, in the given order, least significant 6 bits first.
+func encodeShaCrypt(digest []byte, order []int) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(order); i += 3 {
+		var w uint
+		n := len(order) - i
+		if n > 3 {
+			n = 3
+		}
+
+		// each group is read big-endian, and the final group may be short.
+		for _, idx := range order[i : i+n] {
+			w = w<<8 | uint(digest[idx])
+		}
+
+		for j := 0; j <= n; j++ {
+			sb.WriteByte(shaCryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+
+	return sb.String()
+}
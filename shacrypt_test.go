@@ -0,0 +1,71 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCrypt(t *testing.T) {
+	// entries generated using openssl passwd and glibc's crypt. Salts longer than 16
+	// characters are truncated, so "Long Salt" and "Truncated Salt" share a checksum,
+	// as in the specification's test vectors.
+	testCases := []struct {
+		Name  string
+		Pwd   string
+		Entry string
+	}{
+		{Name: "SHA256", Pwd: "Hello world!", Entry: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{Name: "SHA512", Pwd: "Hello world!", Entry: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{Name: "SHA256 Rounds", Pwd: "Hello world!", Entry: "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+		{Name: "SHA512 Rounds", Pwd: "password", Entry: "$6$rounds=1000$abcdefgh$wuAp2XWwaaguzVxZjeM2bd1yLSqbC/I9sr9DFeOfIPoAZiIj3ecL6rf9ibuAg8RDmh1vqbaeL0NSLJtGPF7b60"},
+		{Name: "Long Salt", Pwd: "This is just a test", Entry: "$6$rounds=5000$toolongsaltstring$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+		{Name: "Truncated Salt", Pwd: "This is just a test", Entry: "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+		{Name: "Empty Password", Pwd: "", Entry: "$5$short$cLqQczNe1fmrPIy0KIrfq/WfsyygGH83SzXaZDNpS53"},
+		{Name: "Long Password", Pwd: strings.Repeat("x", 100), Entry: "$5$rounds=1200$longer$6JfBt20QoF2730xLAwuJO7iZsjoujhsWtQoqtP1uRf4"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := VerifyCrypt([]byte(tc.Pwd), tc.Entry)
+			if !ok || err != nil {
+				t.Errorf("expected (true, nil) but got (%v, %v)", ok, err)
+			}
+
+			ok, err = VerifyCrypt([]byte(tc.Pwd+"!"), tc.Entry)
+			if ok || err != nil {
+				t.Errorf("expected (false, nil) but got (%v, %v)", ok, err)
+			}
+		})
+	}
+
+	t.Run("Unsupported Scheme", func(t *testing.T) {
+		_, err := VerifyCrypt([]byte("password"), "$1$saltsalt$qjXMvbEw8oaL.CzflDugX/")
+		if !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("expected '%v' but got '%v'", ErrUnsupportedScheme, err)
+		}
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		for _, entry := range []string{
+			"",
+			"5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+			"$5$saltstring",
+			"$5$rounds=abc$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+			"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc",
+			"$5$a$b$c$d",
+		} {
+			if _, err := VerifyCrypt([]byte("Hello world!"), entry); err != ErrInvalidCrypt {
+				t.Errorf("expected '%v' but got '%v' for %q", ErrInvalidCrypt, err, entry)
+			}
+		}
+	})
+}
+
+func TestClampRounds(t *testing.T) {
+	for n, expected := range map[int]int{0: 1000, 999: 1000, 5000: 5000, 1000000000: 999999999} {
+		if r := clampRounds(n); r != expected {
+			t.Errorf("expected %d rounds for %d, but got %d", expected, n, r)
+		}
+	}
+}
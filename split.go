@@ -0,0 +1,85 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSplit is returned when a hash split by HashSplit can't be rejoined
+// with its salt, as either is in an invalid format, or they don't belong together.
+var ErrInvalidSplit = errors.New("salt and split hash can't be rejoined")
+
+// HashSplit hashes the given password in the same way as Hash, returning the hash
+// split into two parts, so the salt can be stored away from the sub-key, such as in
+// a separate, more protected, table backed by a KMS or HSM:
+//
+//   - headerAndKey is the hash with the salt removed, which is the header, exactly
+//     as it appears in the hash, followed immediately by the sub-key. The header still
+//     records the salt's length, so the salt can be checked when the parts are rejoined.
+//   - salt is the raw salt, exactly as it appears in the hash.
+//
+// Inserting the salt after the header of headerAndKey produces the hash returned by Hash,
+// so stored parts can be joined again to migrate back to a single column.
+//
+// A non-nil error will be returned if a salt could not be generated.
+func (h *hasher) HashSplit(pwd []byte) (headerAndKey, salt []byte, err error) {
+	hash, err := h.Hash(pwd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hdr, err := scanHeader(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerAndKey, salt = splitHash(hash, hdr)
+
+	return headerAndKey, salt, nil
+}
+
+// VerifySplit verifies the password against a hash split by HashSplit, in the same
+// way as Verify, returning a flag which determines whether or not the password matches.
+//
+// A non-nil error wrapping ErrInvalidSplit will be returned if the parts can't be
+// rejoined, for example, if the salt's length is not the length recorded in the header.
+func (h *hasher) VerifySplit(pwd, headerAndKey, salt []byte) (bool, error) {
+	hash, err := joinHash(headerAndKey, salt)
+	if err != nil {
+		return false, err
+	}
+
+	return h.Verify(pwd, hash), nil
+}
+
+// splits the hash, with the scanned header, into its header and sub-key, and its salt.
+func splitHash(hash []byte, hdr header) (headerAndKey, salt []byte) {
+	salt = append([]byte{}, hash[hdr.size:hdr.size+hdr.saltLen]...)
+
+	headerAndKey = make([]byte, 0, len(hash)-hdr.saltLen)
+	headerAndKey = append(headerAndKey, hash[:hdr.size]...)
+	headerAndKey = append(headerAndKey, hash[hdr.size+hdr.saltLen:]...)
+
+	return headerAndKey, salt
+}
+
+// joins a hash split by splitHash, inserting the salt after the header.
+func joinHash(headerAndKey, salt []byte) ([]byte, error) {
+	// the joined hash is the same length as the parts appended, and its header
+	// is the same, so the header can be scanned here, before the salt is in place.
+	hdr, err := scanHeader(append(append([]byte{}, headerAndKey...), salt...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSplit, err)
+	}
+
+	if len(headerAndKey) < hdr.size || hdr.saltLen != len(salt) {
+		return nil, ErrInvalidSplit
+	}
+
+	hash := make([]byte, 0, len(headerAndKey)+len(salt))
+	hash = append(hash, headerAndKey[:hdr.size]...)
+	hash = append(hash, salt...)
+	hash = append(hash, headerAndKey[hdr.size:]...)
+
+	return hash, nil
+}
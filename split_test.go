@@ -0,0 +1,65 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestHashSplit(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	salt := bytes.Repeat([]byte{0x42}, DefaultSaltSize/8)
+	h, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey,
+		WithSaltSource(fixedSource{salt: salt}), WithTimestamp(true))
+
+	hash, _ := h.Hash(pwd)
+	headerAndKey, splitSalt, err := h.HashSplit(pwd)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !bytes.Equal(splitSalt, salt) {
+		t.Errorf("expected the salt to be '%x' but got '%x'", salt, splitSalt)
+	}
+
+	hdr, _ := scanHeader(hash)
+	expected := append(append([]byte{}, hash[:hdr.size]...), hash[hdr.size+hdr.saltLen:]...)
+	if !bytes.Equal(headerAndKey, expected) {
+		t.Errorf("expected the header and sub-key to be '%x' but got '%x'", expected, headerAndKey)
+	}
+
+	ok, err := h.VerifySplit(pwd, headerAndKey, splitSalt)
+	if !ok || err != nil {
+		t.Errorf("expected the split hash to be valid, but got '%v', '%v'", ok, err)
+	}
+
+	ok, err = h.VerifySplit([]byte("wrong"), headerAndKey, splitSalt)
+	if ok || err != nil {
+		t.Errorf("expected a mismatch, but got '%v', '%v'", ok, err)
+	}
+
+	testCases := []struct {
+		Name         string
+		HeaderAndKey []byte
+		Salt         []byte
+	}{
+		{"Short Salt", headerAndKey, splitSalt[1:]},
+		{"Long Salt", headerAndKey, append(splitSalt, 0x00)},
+		{"Empty Salt", headerAndKey, nil},
+		{"Truncated Header", headerAndKey[:4], splitSalt},
+		{"Invalid Header", []byte{0xFF}, splitSalt},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ok, err := h.VerifySplit(pwd, tc.HeaderAndKey, tc.Salt)
+			if ok {
+				t.Errorf("expected the split hash to be invalid")
+			}
+
+			if !errors.Is(err, ErrInvalidSplit) {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidSplit, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package hasher
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSaltReuse is returned when a SaltTracker sees a salt which it
+// has already recorded.
+var ErrSaltReuse = errors.New("salt has been generated more than once")
+
+// DefaultSaltTrackerSize is the default number of salts a SaltTracker records.
+const DefaultSaltTrackerSize = 1024
+
+// SaltTracker records recently generated salts, in order to detect
+// collisions. As salts are random, a collision is almost certainly a sign
+// of a broken random number generator.
+//
+// A SaltTracker is intended as a monitoring aid for test and canary
+// environments, rather than production. It only remembers the most recent
+// salts, using a ring buffer, so collisions with older salts go unnoticed.
+//
+// A SaltTracker is safe for concurrent use.
+type SaltTracker struct {
+	mu         sync.Mutex
+	ring       []string
+	next       int
+	seen       map[string]struct{}
+	collisions int
+}
+
+// NewSaltTracker returns a new SaltTracker which remembers the given number
+// of salts. If size is not positive, DefaultSaltTrackerSize is used.
+func NewSaltTracker(size int) *SaltTracker {
+	if size < 1 {
+		size = DefaultSaltTrackerSize
+	}
+
+	return &SaltTracker{
+		ring: make([]string, 0, size),
+		seen: make(map[string]struct{}, size),
+	}
+}
+
+// Track records the given salt, returning ErrSaltReuse if the salt
+// has already been recorded.
+func (t *SaltTracker) Track(salt []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := string(salt)
+	if _, ok := t.seen[s]; ok {
+		t.collisions++
+		return ErrSaltReuse
+	}
+
+	if len(t.ring) < cap(t.ring) {
+		t.ring = append(t.ring, s)
+	} else {
+		// evict the oldest salt.
+		delete(t.seen, t.ring[t.next])
+		t.ring[t.next] = s
+		t.next = (t.next + 1) % len(t.ring)
+	}
+
+	t.seen[s] = struct{}{}
+
+	return nil
+}
+
+// Collisions returns the number of collisions the tracker has seen.
+func (t *SaltTracker) Collisions() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.collisions
+}
@@ -0,0 +1,64 @@
+package hasher
+
+import "testing"
+
+func TestSaltTracker(t *testing.T) {
+	tracker := NewSaltTracker(2)
+
+	if err := tracker.Track([]byte("a")); err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	if err := tracker.Track([]byte("b")); err != nil {
+		t.Errorf("didn't expect to get an error: %v", err)
+	}
+
+	t.Run("Collision", func(t *testing.T) {
+		if err := tracker.Track([]byte("a")); err != ErrSaltReuse {
+			t.Errorf("expected '%v' but got '%v'", ErrSaltReuse, err)
+		}
+
+		if c := tracker.Collisions(); c != 1 {
+			t.Errorf("expected 1 collision, but got %d", c)
+		}
+	})
+
+	t.Run("Eviction", func(t *testing.T) {
+		// "c" evicts "a", the oldest salt.
+		if err := tracker.Track([]byte("c")); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		if err := tracker.Track([]byte("a")); err != nil {
+			t.Errorf("didn't expect to get an error: %v", err)
+		}
+
+		if err := tracker.Track([]byte("c")); err != ErrSaltReuse {
+			t.Errorf("expected '%v' but got '%v'", ErrSaltReuse, err)
+		}
+	})
+
+	t.Run("Default Size", func(t *testing.T) {
+		tracker := NewSaltTracker(0)
+		if cap(tracker.ring) != DefaultSaltTrackerSize {
+			t.Errorf("expected a size of %d, but got %d", DefaultSaltTrackerSize, cap(tracker.ring))
+		}
+	})
+}
+
+func TestWithSaltTracker(t *testing.T) {
+	tracker := NewSaltTracker(DefaultSaltTrackerSize)
+	hasher, _ := New(DefaultIterationCount, DefaultSaltSize, DefaultKeySize, DefaultHashKey, WithSaltTracker(tracker))
+
+	for i := 0; i < 10; i++ {
+		hasher.Hash([]byte("MyTestPassword"))
+	}
+
+	if len(tracker.ring) != 10 {
+		t.Errorf("expected 10 salts to be tracked, but got %d", len(tracker.ring))
+	}
+
+	if c := tracker.Collisions(); c != 0 {
+		t.Errorf("expected no collisions, but got %d", c)
+	}
+}
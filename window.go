@@ -0,0 +1,84 @@
+package hasher
+
+import (
+	"fmt"
+)
+
+// WindowError is returned by VerifyWithReason when a parameter of a hash falls outside
+// the window configured by WithParameterWindow, describing the parameter and the window.
+// It wraps ErrHashTooWeak if the value is below the window, or ErrHashTooStrong if it's
+// above it, which can be matched using errors.Is.
+type WindowError struct {
+	// Field is the name of the parameter, one of "iterations", "saltSize" or "keySize".
+	// Sizes are in bits, as in Config.
+	Field string
+
+	// Value is the hash's value, and Min and Max are the window's bounds, either of which
+	// is 0 if the window is unbounded in that direction.
+	Value int
+	Min   int
+	Max   int
+
+	Err error
+}
+
+// Error returns a description of the value and window, such as "iterations 500 is
+// outside the window [1000, 200000]".
+func (e *WindowError) Error() string {
+	return fmt.Sprintf("hasher: %s %d is outside the window [%d, %d]", e.Field, e.Value, e.Min, e.Max)
+}
+
+// Unwrap returns the error wrapped by e.
+func (e *WindowError) Unwrap() error {
+	return e.Err
+}
+
+// returns true if the window's bounds are valid, as described by WithParameterWindow.
+func validWindow(min, max Config) bool {
+	if min.HashKey != 0 || max.HashKey != 0 {
+		return false
+	}
+
+	for _, b := range [][2]int{
+		{min.IterationCount, max.IterationCount},
+		{min.SaltSize, max.SaltSize},
+		{min.KeySize, max.KeySize},
+	} {
+		if b[0] < 0 || b[1] < 0 || (b[1] != 0 && b[0] > b[1]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// returns a non-nil *WindowError if the iteration count, or the salt or sub-key sizes,
+// in bytes, fall outside the hasher's parameter window, checking them in that order.
+func (h *hasher) checkWindow(iterCnt, saltLen, keyLen int) error {
+	if h.winMin == nil {
+		return nil
+	}
+
+	if err := checkBounds("iterations", iterCnt, h.winMin.IterationCount, h.winMax.IterationCount); err != nil {
+		return err
+	}
+
+	if err := checkBounds("saltSize", saltLen*8, h.winMin.SaltSize, h.winMax.SaltSize); err != nil {
+		return err
+	}
+
+	return checkBounds("keySize", keyLen*8, h.winMin.KeySize, h.winMax.KeySize)
+}
+
+// returns a non-nil *WindowError if the value is below min, or above max,
+// either of which is ignored if 0.
+func checkBounds(field string, value, min, max int) error {
+	switch {
+	case min != 0 && value < min:
+		return &WindowError{Field: field, Value: value, Min: min, Max: max, Err: ErrHashTooWeak}
+	case max != 0 && value > max:
+		return &WindowError{Field: field, Value: value, Min: min, Max: max, Err: ErrHashTooStrong}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package hasher
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+// Errors returned when wrapping and unwrapping hashes.
+var (
+	ErrInvalidNonce       = errors.New("nonce is not the size required by the aead")
+	ErrInvalidWrappedHash = errors.New("wrapped hash could not be unwrapped")
+)
+
+// WrapHash encrypts the hash with the given AEAD, such as AES-GCM, for storing hashes
+// encrypted at rest, returning the nonce followed by the ciphertext, so it can be
+// unwrapped with nothing but the AEAD, see UnwrapHash. Keys are managed by the caller,
+// and the nonce must never be reused with the same key, so should be random, from
+// crypto/rand, or a counter.
+//
+// A non-nil error, ErrInvalidNonce, will be returned if the nonce is not the AEAD's
+// nonce size.
+func WrapHash(hash []byte, aead cipher.AEAD, nonce []byte) ([]byte, error) {
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidNonce
+	}
+
+	out := make([]byte, len(nonce), len(nonce)+len(hash)+aead.Overhead())
+	copy(out, nonce)
+
+	return aead.Seal(out, nonce, hash, nil), nil
+}
+
+// UnwrapHash decrypts a hash wrapped by WrapHash, using the same AEAD, returning
+// the hash, which can then be verified as usual.
+//
+// A non-nil error wrapping ErrInvalidWrappedHash will be returned if the wrapped hash
+// is too short to contain a nonce, or it can't be decrypted, as it's been tampered
+// with, or was wrapped with a different key.
+func UnwrapHash(wrapped []byte, aead cipher.AEAD) ([]byte, error) {
+	n := aead.NonceSize()
+	if len(wrapped) < n+aead.Overhead() {
+		return nil, ErrInvalidWrappedHash
+	}
+
+	hash, err := aead.Open(nil, wrapped[:n], wrapped[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWrappedHash, err)
+	}
+
+	return hash, nil
+}
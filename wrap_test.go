@@ -0,0 +1,80 @@
+package hasher
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+// returns a new AES-GCM AEAD, using the given key.
+func newGCM(t *testing.T, key []byte) cipher.AEAD {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	return aead
+}
+
+func TestWrapHash(t *testing.T) {
+	pwd := []byte("MyTestPassword")
+	hash := mustHash(t, pwd)
+	aead := newGCM(t, bytes.Repeat([]byte{0x01}, 32))
+	nonce := bytes.Repeat([]byte{0x02}, aead.NonceSize())
+
+	wrapped, err := WrapHash(hash, aead, nonce)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !bytes.HasPrefix(wrapped, nonce) {
+		t.Errorf("expected the wrapped hash to start with the nonce")
+	}
+
+	if bytes.Contains(wrapped, hash) {
+		t.Errorf("expected the hash to be encrypted")
+	}
+
+	unwrapped, err := UnwrapHash(wrapped, aead)
+	if err != nil {
+		t.Fatalf("didn't expect to get an error: %v", err)
+	}
+
+	if !Verify(pwd, unwrapped) {
+		t.Errorf("expected unwrapped hash to be valid")
+	}
+
+	t.Run("Invalid Nonce", func(t *testing.T) {
+		if _, err := WrapHash(hash, aead, nonce[1:]); err != ErrInvalidNonce {
+			t.Errorf("expected '%v' but got '%v'", ErrInvalidNonce, err)
+		}
+	})
+
+	testCases := []struct {
+		Name    string
+		Wrapped []byte
+		Aead    cipher.AEAD
+	}{
+		{"Tampered", append(append([]byte{}, wrapped[:len(wrapped)-1]...), wrapped[len(wrapped)-1]^0xFF), aead},
+		{"Wrong Key", wrapped, newGCM(t, bytes.Repeat([]byte{0x03}, 32))},
+		{"Truncated", wrapped[:aead.NonceSize()], aead},
+		{"Empty", nil, aead},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if _, err := UnwrapHash(tc.Wrapped, tc.Aead); !errors.Is(err, ErrInvalidWrappedHash) {
+				t.Errorf("expected '%v' but got '%v'", ErrInvalidWrappedHash, err)
+			}
+		})
+	}
+}